@@ -53,6 +53,11 @@ type Identifier struct {
 	Token        token.Token
 	HoldsVarType token.Token
 	Value        string
+
+	// set on the last parameter of a function declared as `int ...nums`
+	Variadic bool
+	// set on parameters declared as `int? x`, they also take null
+	Nullable bool
 }
 
 func (i *Identifier) expressionNode() {}
@@ -62,15 +67,28 @@ func (i *Identifier) TokenLiteral() string {
 func (i *Identifier) GetTreeFormat() string { return "" }
 func (i *Identifier) String() string {
 	var str string
-	str = fmt.Sprintf("%s %s", i.HoldsVarType.Literal, i.TokenLiteral())
+	varType := i.HoldsVarType.Literal
+	if i.Nullable {
+		varType += "?"
+	}
+	if i.Variadic {
+		str = fmt.Sprintf("%s ...%s", varType, i.TokenLiteral())
+	} else {
+		str = fmt.Sprintf("%s %s", varType, i.TokenLiteral())
+	}
 
 	return str
 }
 
 type IntStatement struct {
-	Token token.Token // token.INT
-	Name  *Identifier
-	Value Expression
+	Token    token.Token // token.INT
+	Name     *Identifier
+	Value    Expression
+	Nullable bool // declared as `int? x`, so it can hold null
+	Pub      bool // declared as `pub int x`, see FunctionStatement.Pub
+	Const    bool // declared as `const int x`, it can't be assigned again
+	// the comment right before the declaration, like FunctionStatement.Doc
+	Doc string
 }
 
 func (is *IntStatement) expressionNode()       {}
@@ -79,7 +97,11 @@ func (is *IntStatement) TokenLiteral() string  { return is.Token.Literal }
 func (is *IntStatement) GetTreeFormat() string { return "" }
 func (is *IntStatement) String() string {
 	var out bytes.Buffer
+	writeModifiers(&out, is.Pub, is.Const)
 	out.WriteString(is.TokenLiteral())
+	if is.Nullable {
+		out.WriteString("?")
+	}
 	out.WriteString(is.Name.String())
 	out.WriteString(" = ")
 	if is.Value != nil {
@@ -88,6 +110,16 @@ func (is *IntStatement) String() string {
 	return out.String()
 }
 
+// writeModifiers writes the `pub` and `const` a declaration starts with
+func writeModifiers(out *bytes.Buffer, pub, constant bool) {
+	if pub {
+		out.WriteString("pub ")
+	}
+	if constant {
+		out.WriteString("const ")
+	}
+}
+
 type IntegerLiteral struct {
 	Token token.Token
 	Value int64
@@ -98,6 +130,46 @@ func (il *IntegerLiteral) TokenLiteral() string  { return il.Token.Literal }
 func (il *IntegerLiteral) String() string        { return il.Token.Literal }
 func (il *IntegerLiteral) GetTreeFormat() string { return il.Token.Literal }
 
+type FloatStatement struct {
+	Token    token.Token // token.Keyword_FLOAT
+	Name     *Identifier
+	Value    Expression
+	Nullable bool // declared as `float? x`, so it can hold null
+	Pub      bool // declared as `pub float x`, see FunctionStatement.Pub
+	Const    bool // declared as `const float x`, it can't be assigned again
+	// the comment right before the declaration, like FunctionStatement.Doc
+	Doc string
+}
+
+func (fs *FloatStatement) expressionNode()       {}
+func (fs *FloatStatement) statementNode()        {}
+func (fs *FloatStatement) TokenLiteral() string  { return fs.Token.Literal }
+func (fs *FloatStatement) GetTreeFormat() string { return "" }
+func (fs *FloatStatement) String() string {
+	var out bytes.Buffer
+	writeModifiers(&out, fs.Pub, fs.Const)
+	out.WriteString(fs.TokenLiteral())
+	if fs.Nullable {
+		out.WriteString("?")
+	}
+	out.WriteString(fs.Name.String())
+	out.WriteString(" = ")
+	if fs.Value != nil {
+		out.WriteString(fs.Value.String())
+	}
+	return out.String()
+}
+
+type FloatLiteral struct {
+	Token token.Token
+	Value float64
+}
+
+func (fl *FloatLiteral) expressionNode()       {}
+func (fl *FloatLiteral) TokenLiteral() string  { return fl.Token.Literal }
+func (fl *FloatLiteral) String() string        { return fl.Token.Literal }
+func (fl *FloatLiteral) GetTreeFormat() string { return fl.Token.Literal }
+
 type ReturnStatement struct {
 	Token       token.Token // the 'return' token
 	ReturnValue Expression
@@ -184,6 +256,15 @@ func (b *Boolean) TokenLiteral() string  { return b.Token.Literal }
 func (b *Boolean) String() string        { return b.Token.Literal }
 func (b *Boolean) GetTreeFormat() string { return "" }
 
+type Null struct {
+	Token token.Token
+}
+
+func (n *Null) expressionNode()       {}
+func (n *Null) TokenLiteral() string  { return n.Token.Literal }
+func (n *Null) String() string        { return n.Token.Literal }
+func (n *Null) GetTreeFormat() string { return "" }
+
 type BlockStatement struct {
 	Token      token.Token // the { token
 	Statements []Statement
@@ -250,8 +331,16 @@ type FunctionStatement struct {
 	Token      token.Token // The 'fn' token
 	Parameters []*Identifier
 	ReturnType token.Token
-	FnName     string
-	Body       *BlockStatement
+	// the return type was written as `int?`, the function may return null
+	NullableReturn bool
+	FnName         string
+	Body           *BlockStatement
+	// declared as `pub fn`, it is part of the API `lim doc` documents. lim
+	// has no modules yet so it changes nothing when running.
+	Pub bool
+	// the comment right before the function without // or /* */, only
+	// set when the lexer keeps comments
+	Doc string
 }
 
 // func (fl *FunctionLiteral) expressionNode()      {}
@@ -266,6 +355,9 @@ func (fl *FunctionStatement) String() string {
 		params = append(params, p.String())
 	}
 
+	if fl.Pub {
+		out.WriteString("pub ")
+	}
 	out.WriteString(fl.TokenLiteral())
 	out.WriteString(" ")
 	out.WriteString(fl.FnName)
@@ -273,6 +365,9 @@ func (fl *FunctionStatement) String() string {
 	out.WriteString(strings.Join(params, ", "))
 	out.WriteString(") ")
 	out.WriteString(fl.ReturnType.Literal)
+	if fl.NullableReturn {
+		out.WriteString("?")
+	}
 	out.WriteString(" {")
 	out.WriteString("\n")
 	out.WriteString(fl.Body.String())
@@ -307,9 +402,14 @@ func (ce *CallExpression) String() string {
 }
 
 type BoolStatement struct {
-	Token token.Token // token.BOOL
-	Name  *Identifier
-	Value Expression
+	Token    token.Token // token.BOOL
+	Name     *Identifier
+	Value    Expression
+	Nullable bool // declared as `bool? x`, so it can hold null
+	Pub      bool // declared as `pub bool x`, see FunctionStatement.Pub
+	Const    bool // declared as `const bool x`, it can't be assigned again
+	// the comment right before the declaration, like FunctionStatement.Doc
+	Doc string
 }
 
 func (is *BoolStatement) expressionNode()       {}
@@ -320,7 +420,11 @@ func (is *BoolStatement) TokenLiteral() string {
 }
 func (is *BoolStatement) String() string {
 	var out bytes.Buffer
+	writeModifiers(&out, is.Pub, is.Const)
 	out.WriteString(is.TokenLiteral())
+	if is.Nullable {
+		out.WriteString("?")
+	}
 	out.WriteString(is.Name.String())
 	out.WriteString("= ")
 	if is.Value != nil {
@@ -340,10 +444,42 @@ func (s *StringVal) TokenLiteral() string  { return s.Token.Literal }
 func (s *StringVal) String() string        { return s.Value }
 func (s *StringVal) GetTreeFormat() string { return "" }
 
+// InterpolatedString is a string like "a ${x} b", Parts alternate between
+// the text around the expressions (as *StringVal) and the expressions, it
+// starts and ends with text.
+type InterpolatedString struct {
+	Token token.Token // the TEMPLATE_HEAD token
+	Parts []Expression
+}
+
+func (is *InterpolatedString) expressionNode()       {}
+func (is *InterpolatedString) TokenLiteral() string  { return is.Token.Literal }
+func (is *InterpolatedString) GetTreeFormat() string { return "" }
+func (is *InterpolatedString) String() string {
+	var out bytes.Buffer
+	out.WriteString(`"`)
+	for i, part := range is.Parts {
+		if i%2 == 0 {
+			out.WriteString(part.String())
+			continue
+		}
+		out.WriteString("${")
+		out.WriteString(part.String())
+		out.WriteString("}")
+	}
+	out.WriteString(`"`)
+	return out.String()
+}
+
 type StringStatement struct {
-	Token token.Token // token.STRING
-	Name  *Identifier
-	Value Expression
+	Token    token.Token // token.STRING
+	Name     *Identifier
+	Value    Expression
+	Nullable bool // declared as `string? x`, so it can hold null
+	Pub      bool // declared as `pub string x`, see FunctionStatement.Pub
+	Const    bool // declared as `const string x`, it can't be assigned again
+	// the comment right before the declaration, like FunctionStatement.Doc
+	Doc string
 }
 
 func (ss *StringStatement) expressionNode()       {}
@@ -354,7 +490,11 @@ func (ss *StringStatement) TokenLiteral() string {
 }
 func (ss *StringStatement) String() string {
 	var out bytes.Buffer
+	writeModifiers(&out, ss.Pub, ss.Const)
 	out.WriteString(ss.TokenLiteral())
+	if ss.Nullable {
+		out.WriteString("?")
+	}
 	out.WriteString(ss.Name.String())
 	out.WriteString("= ")
 	if ss.Value != nil {
@@ -369,6 +509,8 @@ type ArrayLiteral struct {
 	Name     *Identifier
 	Type     token.Token
 	Elements []Expression
+	// set instead of Elements when the array is the result of an expression
+	Value Expression
 }
 
 func (al *ArrayLiteral) expressionNode()       {}
@@ -376,6 +518,9 @@ func (ss *ArrayLiteral) statementNode()        {}
 func (al *ArrayLiteral) TokenLiteral() string  { return al.Token.Literal }
 func (al *ArrayLiteral) GetTreeFormat() string { return "" }
 func (al *ArrayLiteral) String() string {
+	if al.Value != nil {
+		return al.Value.String()
+	}
 	var out bytes.Buffer
 	elements := []string{}
 	for _, el := range al.Elements {
@@ -408,3 +553,64 @@ func (ie *IndexExpression) String() string {
 	out.WriteString("])")
 	return out.String()
 }
+
+type ReassignStatement struct {
+	Token token.Token // the '=' token
+	Name  *Identifier
+	Value Expression
+}
+
+func (rs *ReassignStatement) statementNode()        {}
+func (rs *ReassignStatement) TokenLiteral() string  { return rs.Token.Literal }
+func (rs *ReassignStatement) GetTreeFormat() string { return "" }
+func (rs *ReassignStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString(rs.Name.Value)
+	out.WriteString(" = ")
+	if rs.Value != nil {
+		out.WriteString(rs.Value.String())
+	}
+	return out.String()
+}
+
+type MemberExpression struct {
+	Token    token.Token // the '.' or '?.' token
+	Object   Expression
+	Property *Identifier
+	// `a?.b`, null instead of an error when a is null
+	Optional bool
+}
+
+func (me *MemberExpression) expressionNode()       {}
+func (me *MemberExpression) TokenLiteral() string  { return me.Token.Literal }
+func (me *MemberExpression) GetTreeFormat() string { return "" }
+func (me *MemberExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString(me.Object.String())
+	if me.Optional {
+		out.WriteString("?.")
+	} else {
+		out.WriteString(".")
+	}
+	out.WriteString(me.Property.Value)
+	return out.String()
+}
+
+type MemberAssignStatement struct {
+	Token  token.Token // the '=' token
+	Target *MemberExpression
+	Value  Expression
+}
+
+func (ms *MemberAssignStatement) statementNode()        {}
+func (ms *MemberAssignStatement) TokenLiteral() string  { return ms.Token.Literal }
+func (ms *MemberAssignStatement) GetTreeFormat() string { return "" }
+func (ms *MemberAssignStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString(ms.Target.String())
+	out.WriteString(" = ")
+	if ms.Value != nil {
+		out.WriteString(ms.Value.String())
+	}
+	return out.String()
+}
@@ -0,0 +1,228 @@
+// Package doc pulls the documentation out of lim source files, it backs the
+// `lim doc` command.
+//
+// The documented API is the top-level functions and constants declared with
+// pub, like `pub fn add(int a, int b) int` and `pub const int limit = 10`.
+package doc
+
+import (
+	"fmt"
+	"limLang/ast"
+	"limLang/lexer"
+	"limLang/parser"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Library is the documentation of one or more lim files.
+type Library struct {
+	Name   string
+	Consts []*Const
+	Funcs  []*Func
+}
+
+// Const is a documented constant.
+type Const struct {
+	Name  string
+	Type  string
+	Value string // the expression it was declared with
+	Doc   string
+	File  string
+	Line  int
+}
+
+// Signature is the constant the way it is declared, like
+// `const int limit = 10`.
+func (c *Const) Signature() string {
+	return fmt.Sprintf("const %s %s = %s", c.Type, c.Name, c.Value)
+}
+
+// Func is a documented function.
+type Func struct {
+	Name       string
+	Params     []Param
+	ReturnType string // empty if it returns nothing
+	Doc        string
+	File       string
+	Line       int
+}
+
+type Param struct {
+	Name     string
+	Type     string
+	Variadic bool
+}
+
+// Signature is the function the way it is declared, like
+// `fn add(int a, int b) int`.
+func (f *Func) Signature() string {
+	params := make([]string, len(f.Params))
+	for i, param := range f.Params {
+		if param.Variadic {
+			params[i] = fmt.Sprintf("%s ...%s", param.Type, param.Name)
+		} else {
+			params[i] = fmt.Sprintf("%s %s", param.Type, param.Name)
+		}
+	}
+	sig := fmt.Sprintf("fn %s(%s)", f.Name, strings.Join(params, ", "))
+	if f.ReturnType != "" {
+		sig += " " + f.ReturnType
+	}
+	return sig
+}
+
+// Load reads the documentation of path, a .lim file or a directory of
+// them. The library is named after the file or directory.
+func Load(path string) (*Library, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{path}
+	if info.IsDir() {
+		files, err = filepath.Glob(filepath.Join(path, "*.lim"))
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no .lim files in %s", path)
+		}
+	}
+
+	lib := &Library{Name: strings.TrimSuffix(filepath.Base(path), ".lim")}
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		part, err := Parse(file, string(src))
+		if err != nil {
+			return nil, err
+		}
+		lib.Consts = append(lib.Consts, part.Consts...)
+		lib.Funcs = append(lib.Funcs, part.Funcs...)
+	}
+	sort.SliceStable(lib.Consts, func(i, j int) bool { return lib.Consts[i].Name < lib.Consts[j].Name })
+	sort.SliceStable(lib.Funcs, func(i, j int) bool { return lib.Funcs[i].Name < lib.Funcs[j].Name })
+	return lib, nil
+}
+
+// Parse gets the pub top-level functions and constants of src, file is
+// only used in errors and the File fields. The library has no name.
+func Parse(file, src string) (*Library, error) {
+	l := lexer.New(src)
+	l.KeepComments = true
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, fmt.Errorf("%s: %s", file, strings.Join(p.Errors(), "\n"))
+	}
+
+	lib := &Library{Consts: []*Const{}, Funcs: []*Func{}}
+	for _, stmt := range program.Statements {
+		if c := constant(stmt); c != nil {
+			c.File = file
+			lib.Consts = append(lib.Consts, c)
+			continue
+		}
+		fn, ok := stmt.(*ast.FunctionStatement)
+		if !ok || !fn.Pub {
+			continue
+		}
+		f := &Func{
+			Name:       fn.FnName,
+			ReturnType: typeName(fn.ReturnType.Literal, fn.NullableReturn),
+			Doc:        fn.Doc,
+			File:       file,
+			Line:       fn.Token.Line,
+		}
+		for _, param := range fn.Parameters {
+			f.Params = append(f.Params, Param{Name: param.Value, Type: typeName(param.HoldsVarType.Literal, param.Nullable), Variadic: param.Variadic})
+		}
+		lib.Funcs = append(lib.Funcs, f)
+	}
+	return lib, nil
+}
+
+// constant is the documentation of stmt if it declares a pub constant
+func constant(stmt ast.Statement) *Const {
+	var c *Const
+	var value ast.Expression
+	switch stmt := stmt.(type) {
+	case *ast.IntStatement:
+		if stmt.Pub && stmt.Const {
+			c = &Const{Name: stmt.Name.Value, Type: typeName(stmt.Token.Literal, stmt.Nullable), Doc: stmt.Doc, Line: stmt.Token.Line}
+			value = stmt.Value
+		}
+	case *ast.FloatStatement:
+		if stmt.Pub && stmt.Const {
+			c = &Const{Name: stmt.Name.Value, Type: typeName(stmt.Token.Literal, stmt.Nullable), Doc: stmt.Doc, Line: stmt.Token.Line}
+			value = stmt.Value
+		}
+	case *ast.BoolStatement:
+		if stmt.Pub && stmt.Const {
+			c = &Const{Name: stmt.Name.Value, Type: typeName(stmt.Token.Literal, stmt.Nullable), Doc: stmt.Doc, Line: stmt.Token.Line}
+			value = stmt.Value
+		}
+	case *ast.StringStatement:
+		if stmt.Pub && stmt.Const {
+			c = &Const{Name: stmt.Name.Value, Type: typeName(stmt.Token.Literal, stmt.Nullable), Doc: stmt.Doc, Line: stmt.Token.Line}
+			value = stmt.Value
+		}
+	}
+	if c == nil {
+		return nil
+	}
+	// plain strings print without their quotes
+	if str, ok := value.(*ast.StringVal); ok {
+		c.Value = strconv.Quote(str.Value)
+	} else {
+		c.Value = strings.TrimSpace(value.String())
+	}
+	return c
+}
+
+// typeName adds the ? of nullable types, `int?`
+func typeName(name string, nullable bool) string {
+	if nullable && name != "" {
+		return name + "?"
+	}
+	return name
+}
+
+// Lookup finds the function called name, nil if there is none.
+func (lib *Library) Lookup(name string) *Func {
+	for _, f := range lib.Funcs {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// LookupConst finds the constant called name, nil if there is none.
+func (lib *Library) LookupConst(name string) *Const {
+	for _, c := range lib.Consts {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// paragraphs splits doc text at blank lines
+func paragraphs(doc string) []string {
+	if doc == "" {
+		return nil
+	}
+	paras := []string{}
+	for _, para := range strings.Split(doc, "\n\n") {
+		if para = strings.TrimSpace(para); para != "" {
+			paras = append(paras, para)
+		}
+	}
+	return paras
+}
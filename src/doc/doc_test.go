@@ -0,0 +1,183 @@
+package doc
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const source = `// add adds a and b, see [sub] for the opposite.
+//
+// It never fails.
+pub fn add(int a, int b) int { return a + b }
+
+// sub subtracts b from a, unlike [add] and [nothing] <it> can go negative.
+pub fn sub(int a, int b) int { return a - b }
+
+pub fn log(string ...parts) {}
+
+pub fn find(string? name) int? { return null }
+
+// helper isn't pub so it isn't documented
+fn helper() {}
+
+// limit is the most [add] takes.
+pub const int limit = 10 * 2
+pub const string unit = "cm"
+const int private = 1
+pub int notConst = 1
+`
+
+func testLibrary(t *testing.T) *Library {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "mathx.lim"), []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	lib, err := Load(filepath.Join(dir, "mathx.lim"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return lib
+}
+
+func TestLoad(t *testing.T) {
+	lib := testLibrary(t)
+	if lib.Name != "mathx" {
+		t.Errorf("wrong name. got=%q", lib.Name)
+	}
+	tests := []struct {
+		name      string
+		signature string
+		doc       string
+		line      int
+	}{
+		{"add", "fn add(int a, int b) int", "add adds a and b, see [sub] for the opposite.\n\nIt never fails.", 4},
+		{"find", "fn find(string? name) int?", "", 11},
+		{"log", "fn log(string ...parts)", "", 9},
+		{"sub", "fn sub(int a, int b) int", "sub subtracts b from a, unlike [add] and [nothing] <it> can go negative.", 7},
+	}
+	if len(lib.Funcs) != len(tests) {
+		t.Fatalf("expected %d functions. got=%d", len(tests), len(lib.Funcs))
+	}
+	for i, tt := range tests {
+		f := lib.Funcs[i]
+		if f.Name != tt.name || f.Signature() != tt.signature || f.Doc != tt.doc || f.Line != tt.line {
+			t.Errorf("funcs[%d] wrong. expected=%s %q %q line %d, got=%s %q %q line %d",
+				i, tt.name, tt.signature, tt.doc, tt.line, f.Name, f.Signature(), f.Doc, f.Line)
+		}
+	}
+
+	if lib.Lookup("sub") != lib.Funcs[3] || lib.Lookup("nothing") != nil || lib.Lookup("helper") != nil {
+		t.Errorf("wrong lookup results")
+	}
+
+	consts := []struct {
+		name      string
+		signature string
+		doc       string
+		line      int
+	}{
+		{"limit", "const int limit = (10 * 2)", "limit is the most [add] takes.", 17},
+		{"unit", `const string unit = "cm"`, "", 18},
+	}
+	if len(lib.Consts) != len(consts) {
+		t.Fatalf("expected %d constants. got=%d", len(consts), len(lib.Consts))
+	}
+	for i, tt := range consts {
+		c := lib.Consts[i]
+		if c.Name != tt.name || c.Signature() != tt.signature || c.Doc != tt.doc || c.Line != tt.line {
+			t.Errorf("consts[%d] wrong. expected=%s %q %q line %d, got=%s %q %q line %d",
+				i, tt.name, tt.signature, tt.doc, tt.line, c.Name, c.Signature(), c.Doc, c.Line)
+		}
+	}
+	if lib.LookupConst("unit") != lib.Consts[1] || lib.LookupConst("private") != nil {
+		t.Errorf("wrong constant lookup results")
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Load(dir); err == nil || !strings.Contains(err.Error(), "no .lim files") {
+		t.Errorf("expected an error for an empty directory. got=%v", err)
+	}
+	bad := filepath.Join(dir, "bad.lim")
+	os.WriteFile(bad, []byte("string s = \"open"), 0o644)
+	if _, err := Load(dir); err == nil || !strings.HasPrefix(err.Error(), bad+": line 1") {
+		t.Errorf("expected the parse error. got=%v", err)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	var out strings.Builder
+	if err := testLibrary(t).Markdown(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# mathx\n\n- [limit](#limit)\n- [unit](#unit)\n- [add](#add)\n- [find](#find)\n- [log](#log)\n- [sub](#sub)\n",
+		"## limit\n\n```\nconst int limit = (10 * 2)\n```\n\nlimit is the most [add](#add) takes.\n",
+		"## add\n\n```\nfn add(int a, int b) int\n```\n\nadd adds a and b, see [sub](#sub) for the opposite.\n\nIt never fails.\n",
+		"unlike [add](#add) and [nothing] <it>",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("markdown is missing %q. got=\n%s", want, out.String())
+		}
+	}
+}
+
+func TestHTML(t *testing.T) {
+	var out strings.Builder
+	if err := testLibrary(t).HTML(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<li><a href="#add">add</a></li>`,
+		`<h2 id="sub">sub</h2>`,
+		`<h2 id="limit">limit</h2>`,
+		`<pre>fn log(string ...parts)</pre>`,
+		`<p>sub subtracts b from a, unlike <a href="#add">add</a> and [nothing] &lt;it&gt; can go negative.</p>`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("html is missing %q. got=\n%s", want, out.String())
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	var out strings.Builder
+	testLibrary(t).Lookup("add").Text(&out)
+	expected := "fn add(int a, int b) int\n    add adds a and b, see [sub] for the opposite.\n\n    It never fails.\n"
+	if out.String() != expected {
+		t.Errorf("wrong text. expected=%q, got=%q", expected, out.String())
+	}
+
+	out.Reset()
+	testLibrary(t).LookupConst("unit").Text(&out)
+	if out.String() != "const string unit = \"cm\"\n" {
+		t.Errorf("wrong text for a constant. got=%q", out.String())
+	}
+}
+
+func TestAnchorsDontCollide(t *testing.T) {
+	funcs, err := Parse("case.lim", "pub fn Add() {}\npub fn add() {}\npub fn Case() {}\n// see [Add] and [add]\npub fn doc() {}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lib := &Library{Name: "case", Funcs: funcs.Funcs}
+	var md, html strings.Builder
+	lib.Markdown(&md)
+	lib.HTML(&html)
+	for _, want := range []string{
+		"- [Add](#add)\n- [add](#add-1)\n- [Case](#case-1)\n",
+		"see [Add](#add) and [add](#add-1)",
+	} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("markdown is missing %q. got=\n%s", want, md.String())
+		}
+	}
+	for _, want := range []string{`<h2 id="add">Add</h2>`, `<h2 id="add-1">add</h2>`, `<h2 id="case-1">Case</h2>`} {
+		if !strings.Contains(html.String(), want) {
+			t.Errorf("html is missing %q. got=\n%s", want, html.String())
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package doc
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// links in doc comments are written like [add], they only become links if
+// the library has a function or constant with that name
+var docLink = regexp.MustCompile(`\[(\w+)\]`)
+
+// linkDoc replaces the links in text with link(name), the rest of the text
+// goes through escape
+func (lib *Library) linkDoc(text string, escape func(string) string, link func(name string) string) string {
+	var out strings.Builder
+	last := 0
+	for _, match := range docLink.FindAllStringSubmatchIndex(text, -1) {
+		name := text[match[2]:match[3]]
+		if lib.Lookup(name) == nil && lib.LookupConst(name) == nil {
+			continue
+		}
+		out.WriteString(escape(text[last:match[0]]))
+		out.WriteString(link(name))
+		last = match[1]
+	}
+	out.WriteString(escape(text[last:]))
+	return out.String()
+}
+
+// entry is a constant or function the way the renderers need it
+type entry struct {
+	name, signature, doc string
+}
+
+// entries are the constants and then the functions of lib
+func (lib *Library) entries() []entry {
+	entries := []entry{}
+	for _, c := range lib.Consts {
+		entries = append(entries, entry{c.Name, c.Signature(), c.Doc})
+	}
+	for _, f := range lib.Funcs {
+		entries = append(entries, entry{f.Name, f.Signature(), f.Doc})
+	}
+	return entries
+}
+
+// Markdown writes the documentation with an index at the top, constant and
+// function names are headings and [name] links in docs point at them.
+func (lib *Library) Markdown(w io.Writer) error {
+	var out strings.Builder
+	anchors := lib.anchors()
+	fmt.Fprintf(&out, "# %s\n\n", lib.Name)
+	for _, e := range lib.entries() {
+		fmt.Fprintf(&out, "- [%s](#%s)\n", e.name, anchors[e.name])
+	}
+	for _, e := range lib.entries() {
+		fmt.Fprintf(&out, "\n## %s\n\n```\n%s\n```\n", e.name, e.signature)
+		for _, para := range paragraphs(e.doc) {
+			link := func(name string) string { return fmt.Sprintf("[%s](#%s)", name, anchors[name]) }
+			fmt.Fprintf(&out, "\n%s\n", lib.linkDoc(para, func(s string) string { return s }, link))
+		}
+	}
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+var htmlPage = template.Must(template.New("doc").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Name}}</title>
+<style>
+body { font-family: sans-serif; max-width: 50em; margin: 2em auto; }
+pre { background: #f4f4f4; padding: 0.5em; }
+</style>
+</head>
+<body>
+<h1>{{.Name}}</h1>
+<ul>
+{{- range .Entries}}
+<li><a href="#{{.Anchor}}">{{.Name}}</a></li>
+{{- end}}
+</ul>
+{{- range .Entries}}
+<h2 id="{{.Anchor}}">{{.Name}}</h2>
+<pre>{{.Signature}}</pre>
+{{- range .Doc}}
+<p>{{.}}</p>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
+
+// HTML writes the documentation as a standalone page, like Markdown but
+// with the links as anchors.
+func (lib *Library) HTML(w io.Writer) error {
+	type htmlEntry struct {
+		Name, Anchor, Signature string
+		Doc                     []template.HTML
+	}
+	page := struct {
+		Name    string
+		Entries []htmlEntry
+	}{Name: lib.Name}
+	anchors := lib.anchors()
+	for _, e := range lib.entries() {
+		he := htmlEntry{Name: e.name, Anchor: anchors[e.name], Signature: e.signature}
+		for _, para := range paragraphs(e.doc) {
+			link := func(name string) string {
+				return fmt.Sprintf(`<a href="#%s">%s</a>`, anchors[name], template.HTMLEscapeString(name))
+			}
+			he.Doc = append(he.Doc, template.HTML(lib.linkDoc(para, template.HTMLEscapeString, link)))
+		}
+		page.Entries = append(page.Entries, he)
+	}
+	return htmlPage.Execute(w, page)
+}
+
+// Text writes f for the terminal, the signature and then its doc indented
+// under it.
+func (f *Func) Text(w io.Writer) error {
+	return writeText(w, f.Signature(), f.Doc)
+}
+
+// Text writes c for the terminal like Func.Text.
+func (c *Const) Text(w io.Writer) error {
+	return writeText(w, c.Signature(), c.Doc)
+}
+
+func writeText(w io.Writer, signature, doc string) error {
+	var out strings.Builder
+	fmt.Fprintf(&out, "%s\n", signature)
+	for i, para := range paragraphs(doc) {
+		if i > 0 {
+			out.WriteString("\n")
+		}
+		for _, line := range strings.Split(para, "\n") {
+			fmt.Fprintf(&out, "    %s\n", line)
+		}
+	}
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// anchors are the ids of the headings of the entries by name. Markdown
+// renderers make them lower case and add -1, -2 and so on to the ones that
+// are already taken, so `Add` after `add` becomes add-1. We do the same, in
+// the order the headings come in, and use them for HTML too.
+func (lib *Library) anchors() map[string]string {
+	taken := map[string]bool{strings.ToLower(lib.Name): true}
+	anchors := map[string]string{}
+	for _, e := range lib.entries() {
+		if _, ok := anchors[e.name]; ok {
+			continue
+		}
+		base := strings.ToLower(e.name)
+		id := base
+		for i := 1; taken[id]; i++ {
+			id = fmt.Sprintf("%s-%d", base, i)
+		}
+		taken[id] = true
+		anchors[e.name] = id
+	}
+	return anchors
+}
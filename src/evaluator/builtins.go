@@ -1,13 +1,21 @@
 package evaluator
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"limLang/object"
+	"math/rand"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
 )
 
 var buildtins = map[string]*object.Builtin{
 	"len": &object.Builtin{
-		Fn: func(args ...object.Object) object.Object {
+		Name: "len",
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
 			// return NULL
 			if len(args) != 1 {
 				return newError("wrong number of arguments. got=%d, want=1", len(args))
@@ -24,32 +32,213 @@ var buildtins = map[string]*object.Builtin{
 	},
 
 	"print": &object.Builtin{
-		Fn: func(args ...object.Object) object.Object {
+		Name: "print",
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
 			for _, arg := range args {
-				fmt.Print(arg.Inspect())
-			}
-			return nil
-		},
-	},
-
-	// "push": &object.Builtin{
-	// 	Fn: func(args ...object.Object) object.Object {
-	// 		fmt.Println("wtf is in push ")
-	// 		if len(args) != 2 {
-	// 			return newError("wrong number of arguments. got=%d, want=2",
-	// 				len(args))
-	// 		}
-	// 		if args[0].Type() != object.ARRAY_OBJ {
-	// 			return newError("argument to `push` must be ARRAY, got %s", args[0].Type())
-	// 		}
-	// 		arr := args[0].(*object.Array)
-	// 		length := len(arr.Elements)
-	// 		newElements := make([]object.Object, length+1, length+1)
-	// 		copy(newElements, arr.Elements)
-	// 		newElements[length] = args[1]
-	// 		// return &object.Array{Elements: newElements}
-	// 		return &object.Array{Elements: []object.Object{&object.Integer{Value: 1}}}
-	// 		// return &object.Array{}
-	// 	},
-	// },
+				fmt.Fprint(env.Runtime().Out(), orNull(arg).Inspect())
+			}
+			return NULL
+		},
+	},
+
+	// println puts spaces between its arguments and ends the line
+	"println": &object.Builtin{
+		Name: "println",
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			parts := make([]string, len(args))
+			for i, arg := range args {
+				parts[i] = orNull(arg).Inspect()
+			}
+			fmt.Fprintln(env.Runtime().Out(), strings.Join(parts, " "))
+			return NULL
+		},
+	},
+
+	"printf": &object.Builtin{
+		Name: "printf",
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			str, errObj := formatArguments("printf", args)
+			if errObj != nil {
+				return errObj
+			}
+			fmt.Fprint(env.Runtime().Out(), str)
+			return NULL
+		},
+	},
+
+	"sprintf": &object.Builtin{
+		Name: "sprintf",
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			str, errObj := formatArguments("sprintf", args)
+			if errObj != nil {
+				return errObj
+			}
+			return &object.String{Value: str}
+		},
+	},
+
+	"input": &object.Builtin{
+		Name: "input",
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			if len(args) != 0 {
+				return newError("wrong number of arguments. got=%d, want=0", len(args))
+			}
+			// read one byte at a time so nothing after the line is consumed
+			var line []byte
+			buf := make([]byte, 1)
+			for {
+				n, err := env.Runtime().In().Read(buf)
+				if n == 1 {
+					if buf[0] == '\n' {
+						return &object.String{Value: strings.TrimSuffix(string(line), "\r")}
+					}
+					line = append(line, buf[0])
+				}
+				if err == io.EOF {
+					if len(line) == 0 {
+						return NULL
+					}
+					return &object.String{Value: string(line)}
+				}
+				if err != nil {
+					return newError("could not read input: %s", err)
+				}
+			}
+		},
+	},
+
+	"read_file": &object.Builtin{
+		Name:     "read_file",
+		Requires: []object.Capability{object.CapFSRead},
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			path, errObj := stringArgument("read_file", args, 0)
+			if errObj != nil {
+				return errObj
+			}
+			if !env.Runtime().Capabilities.CanRead(path) {
+				return permissionError("`read_file` can't read %s, it is outside of the fs-read roots", path)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return newError("could not read file: %s", err)
+			}
+			return &object.String{Value: string(data)}
+		},
+	},
+
+	"write_file": &object.Builtin{
+		Name:     "write_file",
+		Requires: []object.Capability{object.CapFSWrite},
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+			path, errObj := stringArgument("write_file", args, 0)
+			if errObj != nil {
+				return errObj
+			}
+			content, errObj := stringArgument("write_file", args, 1)
+			if errObj != nil {
+				return errObj
+			}
+			if !env.Runtime().Capabilities.CanWrite(path) {
+				return permissionError("`write_file` can't write %s, it is outside of the fs-write roots", path)
+			}
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				return newError("could not write file: %s", err)
+			}
+			return NULL
+		},
+	},
+
+	"getenv": &object.Builtin{
+		Name:     "getenv",
+		Requires: []object.Capability{object.CapEnv},
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			name, errObj := stringArgument("getenv", args, 0)
+			if errObj != nil {
+				return errObj
+			}
+			value, ok := os.LookupEnv(name)
+			if !ok {
+				return NULL
+			}
+			return &object.String{Value: value}
+		},
+	},
+
+	// exec runs a program and returns what it wrote to stdout
+	"exec": &object.Builtin{
+		Name:     "exec",
+		Requires: []object.Capability{object.CapExec},
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			if len(args) < 1 {
+				return newError("wrong number of arguments. got=%d, want at least 1", len(args))
+			}
+			cmdArgs := make([]string, len(args))
+			for i := range args {
+				arg, errObj := stringArgument("exec", args, i)
+				if errObj != nil {
+					return errObj
+				}
+				cmdArgs[i] = arg
+			}
+			var stdout, stderr bytes.Buffer
+			cmd := exec.Command(cmdArgs[0], cmdArgs[1:]...)
+			cmd.Stdout = &stdout
+			cmd.Stderr = &stderr
+			if err := cmd.Run(); err != nil {
+				if msg := strings.TrimSpace(stderr.String()); msg != "" {
+					return newError("%s failed: %s: %s", cmdArgs[0], err, msg)
+				}
+				return newError("%s failed: %s", cmdArgs[0], err)
+			}
+			return &object.String{Value: stdout.String()}
+		},
+	},
+
+	// now is the time in milliseconds since the unix epoch
+	"now": &object.Builtin{
+		Name:     "now",
+		Requires: []object.Capability{object.CapClock},
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			if len(args) != 0 {
+				return newError("wrong number of arguments. got=%d, want=0", len(args))
+			}
+			return &object.Integer{Value: time.Now().UnixMilli()}
+		},
+	},
+
+	// random returns a random integer from 0 up to but not including n
+	"random": &object.Builtin{
+		Name:     "random",
+		Requires: []object.Capability{object.CapRandom},
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+			n, ok := args[0].(*object.Integer)
+			if !ok {
+				return newError("argument to `random` must be INTEGER, got %s", typeOf(args[0]))
+			}
+			if n.Value <= 0 {
+				return newError("argument to `random` must be positive, got %d", n.Value)
+			}
+			return &object.Integer{Value: rand.Int63n(n.Value)}
+		},
+	},
+}
+
+func stringArgument(name string, args []object.Object, i int) (string, *object.Error) {
+	str, ok := args[i].(*object.String)
+	if !ok {
+		return "", newError("argument %d to `%s` must be STRING, got %s", i+1, name, typeOf(args[i]))
+	}
+	return str.Value, nil
 }
@@ -0,0 +1,130 @@
+package evaluator
+
+import (
+	"fmt"
+	"limLang/object"
+	"limLang/token"
+	"reflect"
+)
+
+// callbackPanic carries a lim error out of a wrapped function whose Go type
+// has no error result to return it in. Builtins recover it and turn it back
+// into the lim error.
+type callbackPanic struct {
+	err *object.Error
+}
+
+// Invoke calls the lim function fn from Go. args are converted with ToObject
+// and the result is converted back to its natural Go type (int64, float64,
+// string, bool, []interface{}, nil or the value of a host object). The
+// function runs in the environment it was declared in, with the settings and
+// limits of the interpreter it belongs to.
+func Invoke(fn object.Object, args ...interface{}) (interface{}, error) {
+	function, ok := fn.(*object.Function)
+	if !ok {
+		return nil, fmt.Errorf("cannot call %s from Go, want FUNCTION", typeOf(fn))
+	}
+	objArgs := make([]object.Object, len(args))
+	for i, arg := range args {
+		obj, err := ToObject(arg)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d to `%s`: %s", i+1, function.FuncName, err)
+		}
+		objArgs[i] = obj
+	}
+
+	rt := function.Env.Runtime()
+	rt.Begin()
+	result := applyFunction(function, objArgs, token.Token{}, function.Env)
+	rt.End()
+	if errObj, ok := result.(*object.Error); ok {
+		return nil, errObj
+	}
+	if result == nil {
+		return nil, nil
+	}
+	return naturalGoValue(result), nil
+}
+
+// MakeFunc sets the function fptr points to, like a *func(int64) bool, to
+// one that calls the lim function fn. Arguments and results are converted
+// like they are for builtins. If the Go function type ends with an error
+// result lim errors are returned there, otherwise they panic; builtins
+// recover those panics and report the error to the script.
+func MakeFunc(fn object.Object, fptr interface{}) error {
+	ptr := reflect.ValueOf(fptr)
+	if ptr.Kind() != reflect.Pointer || ptr.IsNil() || ptr.Elem().Kind() != reflect.Func {
+		return fmt.Errorf("MakeFunc needs a pointer to a function, got %T", fptr)
+	}
+	wrapped, err := wrapFunction(fn, ptr.Elem().Type())
+	if err != nil {
+		return err
+	}
+	ptr.Elem().Set(wrapped)
+	return nil
+}
+
+func wrapFunction(fn object.Object, t reflect.Type) (reflect.Value, error) {
+	function, ok := fn.(*object.Function)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("cannot use %s as %s", typeOf(fn), t)
+	}
+	returnsError := t.NumOut() > 0 && t.Out(t.NumOut()-1) == errorInterface
+	numResults := t.NumOut()
+	if returnsError {
+		numResults--
+	}
+	if numResults > 1 {
+		return reflect.Value{}, fmt.Errorf("cannot use FUNCTION as %s, lim functions return one value", t)
+	}
+
+	return reflect.MakeFunc(t, func(in []reflect.Value) []reflect.Value {
+		out := make([]reflect.Value, t.NumOut())
+		for i := range out {
+			out[i] = reflect.Zero(t.Out(i))
+		}
+		fail := func(errObj *object.Error) []reflect.Value {
+			if !returnsError {
+				panic(callbackPanic{err: errObj})
+			}
+			var err error = errObj
+			out[len(out)-1] = reflect.ValueOf(&err).Elem()
+			return out
+		}
+
+		args := make([]object.Object, len(in))
+		for i, arg := range in {
+			obj, err := toObject(arg)
+			if err != nil {
+				return fail(newError("argument %d to `%s`: %s", i+1, function.FuncName, err))
+			}
+			args[i] = obj
+		}
+
+		rt := function.Env.Runtime()
+		rt.Begin()
+		result := applyFunction(function, args, token.Token{}, function.Env)
+		rt.End()
+		if errObj, ok := result.(*object.Error); ok {
+			return fail(errObj)
+		}
+		if numResults == 1 {
+			if result == nil {
+				result = NULL
+			}
+			goVal, err := ToGoValue(result, t.Out(0))
+			if err != nil {
+				return fail(newError("result of `%s`: %s", function.FuncName, err))
+			}
+			out[0] = goVal
+		}
+		return out
+	}), nil
+}
+
+func typeOf(obj object.Object) object.ObjectType {
+	if obj == nil {
+		return object.NULL_OBJ
+	}
+	return obj.Type()
+}
@@ -0,0 +1,158 @@
+package evaluator
+
+import (
+	"errors"
+	"limLang/lexer"
+	"limLang/object"
+	"limLang/parser"
+	"testing"
+)
+
+func TestInvoke(t *testing.T) {
+	var handler object.Object
+	builtins := map[string]interface{}{
+		"on": func(fn *object.Function) { handler = fn },
+	}
+	evaluated := testEvalWith(`
+	int offset = 10
+	fn onClick(int x, string label) {
+		if x < 0 {
+			return missing
+		}
+		return label + "!"
+	}
+	fn addOffset(int x) { x + offset }
+	on(onClick)
+	`, builtins)
+	if isError(evaluated) {
+		t.Fatalf("unexpected error: %s", evaluated.Inspect())
+	}
+
+	result, err := Invoke(handler, 1, "ok")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result != "ok!" {
+		t.Errorf("wrong result. got=%#v", result)
+	}
+
+	_, err = Invoke(handler, -1, "ok")
+	var errObj *object.Error
+	if !errors.As(err, &errObj) || errObj.Message != "identifier not found: missing" {
+		t.Errorf("expected the lim error. got=%v", err)
+	}
+	if _, err := Invoke(handler, 1); err == nil {
+		t.Errorf("expected an arity error")
+	}
+	if _, err := Invoke(&object.Integer{Value: 1}); err == nil {
+		t.Errorf("expected an error calling an integer")
+	}
+}
+
+func TestCallbacksUseClosureEnvironment(t *testing.T) {
+	env := object.NewEnvironment()
+	l := lexer.New(`
+	int offset = 10
+	fn addOffset(int x) { x + offset }
+	`)
+	Eval(parser.New(l).ParseProgram(), env)
+	fn, _ := env.Get("addOffset")
+
+	result, err := Invoke(fn, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result != int64(15) {
+		t.Errorf("wrong result. got=%#v", result)
+	}
+
+	// changes to the captured environment are seen by the callback
+	env.Assign("offset", &object.Integer{Value: 100})
+	if result, _ := Invoke(fn, 5); result != int64(105) {
+		t.Errorf("callback doesn't see the closure's environment. got=%#v", result)
+	}
+}
+
+func TestMakeFunc(t *testing.T) {
+	env := object.NewEnvironment()
+	l := lexer.New(`
+	fn isEven(int x) { x % 2 == 0 }
+	fn fails(int x) { x + missing }
+	`)
+	Eval(parser.New(l).ParseProgram(), env)
+	isEvenFn, _ := env.Get("isEven")
+	failsFn, _ := env.Get("fails")
+
+	var isEven func(int64) bool
+	if err := MakeFunc(isEvenFn, &isEven); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !isEven(4) || isEven(3) {
+		t.Errorf("wrapped function returned wrong results")
+	}
+
+	var failsWithErr func(int) (int, error)
+	if err := MakeFunc(failsFn, &failsWithErr); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := failsWithErr(1); err == nil || err.Error() != "identifier not found: missing" {
+		t.Errorf("expected the lim error. got=%v", err)
+	}
+
+	var wrongResult func(int64) string
+	MakeFunc(isEvenFn, &wrongResult)
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected a panic for a result that can't be converted")
+			}
+		}()
+		wrongResult(1)
+	}()
+
+	if err := MakeFunc(isEvenFn, isEven); err == nil {
+		t.Errorf("expected an error for a non pointer")
+	}
+	var twoResults func() (int, int)
+	if err := MakeFunc(isEvenFn, &twoResults); err == nil {
+		t.Errorf("expected an error for a function with two results")
+	}
+}
+
+func TestBuiltinsTakingFuncs(t *testing.T) {
+	builtins := map[string]interface{}{
+		"count_if": func(nums []int64, pred func(int64) bool) int {
+			count := 0
+			for _, n := range nums {
+				if pred(n) {
+					count++
+				}
+			}
+			return count
+		},
+	}
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`int []nums = [1, 2, 3, 4]; fn isEven(int x) { x % 2 == 0 } count_if(nums, isEven)`, 2},
+		{`int []nums = [1, 2]; fn bad(int x) { x + missing } count_if(nums, bad)`, "identifier not found: missing"},
+		{`int []nums = [1, 2]; count_if(nums, 5)`, "argument 2 to `count_if` must be FUNCTION, got INTEGER"},
+	}
+	for _, tt := range tests {
+		evaluated := testEvalWith(tt.input, builtins)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error for %q. got=%T (%+v)", tt.input, evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package evaluator
+
+import (
+	"fmt"
+	"limLang/object"
+)
+
+// checkCapabilities makes sure the interpreter granted everything builtin
+// needs before it runs
+func checkCapabilities(builtin *object.Builtin, env *object.Environment) *object.Error {
+	caps := &env.Runtime().Capabilities
+	for _, capability := range builtin.Requires {
+		if !caps.Has(capability) {
+			return permissionError("`%s` needs the %s capability", builtin.Name, capability)
+		}
+	}
+	return nil
+}
+
+func permissionError(format string, a ...interface{}) *object.Error {
+	err := fmt.Errorf("%w: %s", object.ErrPermission, fmt.Sprintf(format, a...))
+	return &object.Error{Message: err.Error(), Cause: err}
+}
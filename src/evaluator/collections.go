@@ -0,0 +1,458 @@
+package evaluator
+
+import (
+	"limLang/object"
+	"limLang/token"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// the collection builtins never change the array they are given, the ones
+// that "modify" it return a new array instead. They are added in init since
+// the higher-order ones call back into the evaluator, which looks builtins
+// up in buildtins.
+func init() {
+	for _, builtin := range []*object.Builtin{
+		{Name: "push", Fn: builtinPush},
+		{Name: "pop", Fn: builtinPop},
+		{Name: "first", Fn: builtinFirst},
+		{Name: "last", Fn: builtinLast},
+		{Name: "rest", Fn: builtinRest},
+		{Name: "concat", Fn: builtinConcat},
+		{Name: "reverse", Fn: builtinReverse},
+		{Name: "contains", Fn: builtinContains},
+		{Name: "index_of", Fn: builtinIndexOf},
+		{Name: "join", Fn: builtinJoin},
+		{Name: "map", Fn: builtinMap},
+		{Name: "filter", Fn: builtinFilter},
+		{Name: "reduce", Fn: builtinReduce},
+		{Name: "any", Fn: builtinAny},
+		{Name: "all", Fn: builtinAll},
+		{Name: "find", Fn: builtinFind},
+		{Name: "sort_by", Fn: builtinSortBy},
+	} {
+		buildtins[builtin.Name] = builtin
+	}
+}
+
+// push returns a copy of the array with the value added at the end
+func builtinPush(env *object.Environment, args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+	arr, errObj := arrayArgument("push", args, 0)
+	if errObj != nil {
+		return errObj
+	}
+	elements := make([]object.Object, len(arr.Elements), len(arr.Elements)+1)
+	copy(elements, arr.Elements)
+	return &object.Array{Elements: append(elements, args[1])}
+}
+
+// pop returns a copy of the array without its last element, null if it is
+// empty
+func builtinPop(env *object.Environment, args ...object.Object) object.Object {
+	arr, errObj := singleArrayArgument("pop", args)
+	if errObj != nil {
+		return errObj
+	}
+	if len(arr.Elements) == 0 {
+		return NULL
+	}
+	elements := make([]object.Object, len(arr.Elements)-1)
+	copy(elements, arr.Elements)
+	return &object.Array{Elements: elements}
+}
+
+func builtinFirst(env *object.Environment, args ...object.Object) object.Object {
+	arr, errObj := singleArrayArgument("first", args)
+	if errObj != nil {
+		return errObj
+	}
+	if len(arr.Elements) == 0 {
+		return NULL
+	}
+	return arr.Elements[0]
+}
+
+func builtinLast(env *object.Environment, args ...object.Object) object.Object {
+	arr, errObj := singleArrayArgument("last", args)
+	if errObj != nil {
+		return errObj
+	}
+	if len(arr.Elements) == 0 {
+		return NULL
+	}
+	return arr.Elements[len(arr.Elements)-1]
+}
+
+// rest returns everything but the first element, null if the array is empty
+func builtinRest(env *object.Environment, args ...object.Object) object.Object {
+	arr, errObj := singleArrayArgument("rest", args)
+	if errObj != nil {
+		return errObj
+	}
+	if len(arr.Elements) == 0 {
+		return NULL
+	}
+	elements := make([]object.Object, len(arr.Elements)-1)
+	copy(elements, arr.Elements[1:])
+	return &object.Array{Elements: elements}
+}
+
+func builtinConcat(env *object.Environment, args ...object.Object) object.Object {
+	elements := []object.Object{}
+	for i := range args {
+		arr, errObj := arrayArgument("concat", args, i)
+		if errObj != nil {
+			return errObj
+		}
+		elements = append(elements, arr.Elements...)
+	}
+	return &object.Array{Elements: elements}
+}
+
+func builtinReverse(env *object.Environment, args ...object.Object) object.Object {
+	arr, errObj := singleArrayArgument("reverse", args)
+	if errObj != nil {
+		return errObj
+	}
+	elements := make([]object.Object, len(arr.Elements))
+	for i, el := range arr.Elements {
+		elements[len(elements)-1-i] = el
+	}
+	return &object.Array{Elements: elements}
+}
+
+func builtinContains(env *object.Environment, args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+	arr, errObj := arrayArgument("contains", args, 0)
+	if errObj != nil {
+		return errObj
+	}
+	return nativeBoolToBooleanObject(indexOf(arr, args[1]) >= 0)
+}
+
+// index_of returns the index of the first element equal to the value, -1 if
+// there is none
+func builtinIndexOf(env *object.Environment, args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+	arr, errObj := arrayArgument("index_of", args, 0)
+	if errObj != nil {
+		return errObj
+	}
+	return &object.Integer{Value: int64(indexOf(arr, args[1]))}
+}
+
+// join puts the elements together in a string, strings are used as they are
+// and everything else the way print shows it
+func builtinJoin(env *object.Environment, args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+	arr, errObj := arrayArgument("join", args, 0)
+	if errObj != nil {
+		return errObj
+	}
+	sep, errObj := stringArgument("join", args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	parts := make([]string, len(arr.Elements))
+	for i, el := range arr.Elements {
+		if str, ok := el.(*object.String); ok {
+			parts[i] = str.Value
+		} else {
+			parts[i] = orNull(el).Inspect()
+		}
+	}
+	return &object.String{Value: strings.Join(parts, sep)}
+}
+
+func builtinMap(env *object.Environment, args ...object.Object) object.Object {
+	arr, fn, errObj := callbackArguments("map", args)
+	if errObj != nil {
+		return errObj
+	}
+	elements := make([]object.Object, len(arr.Elements))
+	for i, el := range arr.Elements {
+		result := callback(fn, env, el)
+		if isError(result) {
+			return result
+		}
+		elements[i] = result
+	}
+	return &object.Array{Elements: elements}
+}
+
+func builtinFilter(env *object.Environment, args ...object.Object) object.Object {
+	arr, fn, errObj := callbackArguments("filter", args)
+	if errObj != nil {
+		return errObj
+	}
+	elements := []object.Object{}
+	for _, el := range arr.Elements {
+		result := callback(fn, env, el)
+		if isError(result) {
+			return result
+		}
+		if isTruthy(result) {
+			elements = append(elements, el)
+		}
+	}
+	return &object.Array{Elements: elements}
+}
+
+// reduce(arr, fn, initial) calls fn(acc, el) for every element, starting
+// with initial as acc
+func builtinReduce(env *object.Environment, args ...object.Object) object.Object {
+	if len(args) != 3 {
+		return newError("wrong number of arguments. got=%d, want=3", len(args))
+	}
+	arr, fn, errObj := callbackArguments("reduce", args[:2])
+	if errObj != nil {
+		return errObj
+	}
+	acc := args[2]
+	for _, el := range arr.Elements {
+		acc = callback(fn, env, acc, el)
+		if isError(acc) {
+			return acc
+		}
+	}
+	return acc
+}
+
+func builtinAny(env *object.Environment, args ...object.Object) object.Object {
+	arr, fn, errObj := callbackArguments("any", args)
+	if errObj != nil {
+		return errObj
+	}
+	for _, el := range arr.Elements {
+		result := callback(fn, env, el)
+		if isError(result) {
+			return result
+		}
+		if isTruthy(result) {
+			return TRUE
+		}
+	}
+	return FALSE
+}
+
+func builtinAll(env *object.Environment, args ...object.Object) object.Object {
+	arr, fn, errObj := callbackArguments("all", args)
+	if errObj != nil {
+		return errObj
+	}
+	for _, el := range arr.Elements {
+		result := callback(fn, env, el)
+		if isError(result) {
+			return result
+		}
+		if !isTruthy(result) {
+			return FALSE
+		}
+	}
+	return TRUE
+}
+
+// find returns the first element fn is true for, null if there is none
+func builtinFind(env *object.Environment, args ...object.Object) object.Object {
+	arr, fn, errObj := callbackArguments("find", args)
+	if errObj != nil {
+		return errObj
+	}
+	for _, el := range arr.Elements {
+		result := callback(fn, env, el)
+		if isError(result) {
+			return result
+		}
+		if isTruthy(result) {
+			return el
+		}
+	}
+	return NULL
+}
+
+// sort_by returns the elements ordered by the key fn gives for each of
+// them, keys have to be all integers or all strings. Elements with the same
+// key keep their order.
+func builtinSortBy(env *object.Environment, args ...object.Object) object.Object {
+	arr, fn, errObj := callbackArguments("sort_by", args)
+	if errObj != nil {
+		return errObj
+	}
+	keys := make([]object.Object, len(arr.Elements))
+	for i, el := range arr.Elements {
+		key := callback(fn, env, el)
+		if isError(key) {
+			return key
+		}
+		key = orNull(key)
+		if key.Type() != object.INTEGER_OBJ && key.Type() != object.STRING_OBJ {
+			return newError("keys of `sort_by` must be INTEGER or STRING, got %s", key.Type())
+		}
+		if i > 0 && key.Type() != keys[0].Type() {
+			return newError("keys of `sort_by` must all have the same type, got %s and %s", keys[0].Type(), key.Type())
+		}
+		keys[i] = key
+	}
+
+	order := make([]int, len(arr.Elements))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		switch key := keys[order[a]].(type) {
+		case *object.Integer:
+			return key.Value < keys[order[b]].(*object.Integer).Value
+		case *object.String:
+			return key.Value < keys[order[b]].(*object.String).Value
+		}
+		return false
+	})
+
+	elements := make([]object.Object, len(order))
+	for i, idx := range order {
+		elements[i] = arr.Elements[idx]
+	}
+	return &object.Array{Elements: elements}
+}
+
+// callback calls fn for a builtin, functions that don't return anything give
+// null
+func callback(fn object.Object, env *object.Environment, args ...object.Object) object.Object {
+	return orNull(applyFunction(fn, args, token.Token{}, env))
+}
+
+func orNull(obj object.Object) object.Object {
+	if obj == nil {
+		return NULL
+	}
+	return obj
+}
+
+func indexOf(arr *object.Array, value object.Object) int {
+	for i, el := range arr.Elements {
+		if objectsEqual(el, value) {
+			return i
+		}
+	}
+	return -1
+}
+
+// objectsEqual compares values, not identity, so two strings with the same
+// text are equal and so are arrays with equal elements. Integers and floats
+// are compared as numbers, maps and structs of host objects field by field.
+func objectsEqual(a, b object.Object) bool {
+	a, b = orNull(a), orNull(b)
+	if isNumber(a) && isNumber(b) {
+		return compareNumbers(a, "==", b)
+	}
+	if a.Type() != b.Type() {
+		return false
+	}
+	switch a := a.(type) {
+	case *object.Integer:
+		return a.Value == b.(*object.Integer).Value
+	case *object.Float:
+		return a.Value == b.(*object.Float).Value
+	case *object.String:
+		return a.Value == b.(*object.String).Value
+	case *object.Boolean:
+		return a.Value == b.(*object.Boolean).Value
+	case *object.Null:
+		return true
+	case *object.Array:
+		other := b.(*object.Array)
+		if len(a.Elements) != len(other.Elements) {
+			return false
+		}
+		for i := range a.Elements {
+			if !objectsEqual(a.Elements[i], other.Elements[i]) {
+				return false
+			}
+		}
+		return true
+	case *object.Host:
+		return reflect.DeepEqual(a.Value, b.(*object.Host).Value)
+	}
+	return a == b
+}
+
+// compareObjects orders a and b for operator (one of < <= > >=): -1 if a
+// comes first, 1 if b does and 0 if neither. Numbers, strings and arrays of
+// them can be ordered, arrays element by element and the shorter one first
+// when one starts with the other.
+func compareObjects(operator string, a, b object.Object) (int, *object.Error) {
+	a, b = orNull(a), orNull(b)
+	if isNumber(a) && isNumber(b) {
+		if compareNumbers(a, "<", b) {
+			return -1, nil
+		}
+		if compareNumbers(a, ">", b) {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	if a.Type() != b.Type() {
+		return 0, newError("type mismatch: %s %s %s", a.Type(), operator, b.Type())
+	}
+	switch a := a.(type) {
+	case *object.String:
+		return strings.Compare(a.Value, b.(*object.String).Value), nil
+	case *object.Array:
+		other := b.(*object.Array)
+		for i := 0; i < len(a.Elements) && i < len(other.Elements); i++ {
+			if cmp, errObj := compareObjects(operator, a.Elements[i], other.Elements[i]); errObj != nil || cmp != 0 {
+				return cmp, errObj
+			}
+		}
+		switch {
+		case len(a.Elements) < len(other.Elements):
+			return -1, nil
+		case len(a.Elements) > len(other.Elements):
+			return 1, nil
+		}
+		return 0, nil
+	}
+	return 0, newError("unknown operator: %s %s %s", a.Type(), operator, b.Type())
+}
+
+func arrayArgument(name string, args []object.Object, i int) (*object.Array, *object.Error) {
+	arr, ok := args[i].(*object.Array)
+	if !ok {
+		return nil, newError("argument %d to `%s` must be ARRAY, got %s", i+1, name, typeOf(args[i]))
+	}
+	return arr, nil
+}
+
+func singleArrayArgument(name string, args []object.Object) (*object.Array, *object.Error) {
+	if len(args) != 1 {
+		return nil, newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+	return arrayArgument(name, args, 0)
+}
+
+// callbackArguments checks the (array, function) arguments of the
+// higher-order builtins
+func callbackArguments(name string, args []object.Object) (*object.Array, object.Object, *object.Error) {
+	if len(args) != 2 {
+		return nil, nil, newError("wrong number of arguments. got=%d, want=2", len(args))
+	}
+	arr, errObj := arrayArgument(name, args, 0)
+	if errObj != nil {
+		return nil, nil, errObj
+	}
+	switch args[1].(type) {
+	case *object.Function, *object.Builtin:
+		return arr, args[1], nil
+	}
+	return nil, nil, newError("argument 2 to `%s` must be FUNCTION, got %s", name, typeOf(args[1]))
+}
@@ -0,0 +1,320 @@
+package evaluator
+
+import (
+	"fmt"
+	"limLang/object"
+	"math"
+	"reflect"
+)
+
+var (
+	objectInterface = reflect.TypeOf((*object.Object)(nil)).Elem()
+	errorInterface  = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// ToObject converts a Go value to the lim object holding the same value.
+// Integers become INTEGER, floats FLOAT, strings STRING, bools BOOLEAN and
+// slices ARRAY, structs and maps are wrapped as host objects, nil becomes
+// null and lim objects are returned as they are.
+func ToObject(v interface{}) (object.Object, error) {
+	if v == nil {
+		return NULL, nil
+	}
+	return toObject(reflect.ValueOf(v))
+}
+
+func toObject(v reflect.Value) (object.Object, error) {
+	if !v.IsValid() {
+		return NULL, nil
+	}
+	if v.Type().Implements(objectInterface) {
+		if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+			return NULL, nil
+		}
+		return v.Interface().(object.Object), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &object.Integer{Value: v.Int()}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Uint() > math.MaxInt64 {
+			return nil, fmt.Errorf("%d does not fit in an integer", v.Uint())
+		}
+		return &object.Integer{Value: int64(v.Uint())}, nil
+	case reflect.Float32, reflect.Float64:
+		return &object.Float{Value: v.Float()}, nil
+	case reflect.String:
+		return &object.String{Value: v.String()}, nil
+	case reflect.Bool:
+		return nativeBoolToBooleanObject(v.Bool()), nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return &object.Array{Elements: []object.Object{}}, nil
+		}
+		elements := make([]object.Object, v.Len())
+		for i := range elements {
+			el, err := toObject(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = el
+		}
+		return &object.Array{Elements: elements}, nil
+	case reflect.Map:
+		if v.IsNil() || v.Type().Key().Kind() != reflect.String {
+			break
+		}
+		return &object.Host{Value: v.Interface()}, nil
+	case reflect.Struct:
+		// keep pointing at the original struct so scripts can change it
+		if v.CanAddr() {
+			return &object.Host{Value: v.Addr().Interface()}, nil
+		}
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		return &object.Host{Value: ptr.Interface()}, nil
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return NULL, nil
+		}
+		if v.Kind() == reflect.Pointer && v.Elem().Kind() == reflect.Struct {
+			return &object.Host{Value: v.Interface()}, nil
+		}
+		return toObject(v.Elem())
+	}
+	return nil, fmt.Errorf("cannot convert %s to a lim value", v.Type())
+}
+
+// ToGoValue converts obj to a Go value of type t, it is the reverse of
+// ToObject. Converting to interface{} picks the natural Go type for obj.
+func ToGoValue(obj object.Object, t reflect.Type) (reflect.Value, error) {
+	if t.Kind() == reflect.Interface && objectInterface.Implements(t) && t.NumMethod() > 0 {
+		// object.Object or a smaller interface it satisfies
+		return reflect.ValueOf(obj), nil
+	}
+	if t.Kind() == reflect.Func {
+		return wrapFunction(obj, t)
+	}
+	if obj != nil && t.Kind() != reflect.Interface && reflect.TypeOf(obj).AssignableTo(t) {
+		// builtins that want the lim object itself, like *object.Function
+		return reflect.ValueOf(obj), nil
+	}
+	if host, ok := obj.(*object.Host); ok {
+		if v := reflect.ValueOf(host.Value); v.Type().AssignableTo(t) {
+			return v, nil
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		integer, ok := obj.(*object.Integer)
+		if !ok {
+			break
+		}
+		v := reflect.New(t).Elem()
+		if v.OverflowInt(integer.Value) {
+			return reflect.Value{}, fmt.Errorf("%d does not fit in %s", integer.Value, t)
+		}
+		v.SetInt(integer.Value)
+		return v, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		integer, ok := obj.(*object.Integer)
+		if !ok {
+			break
+		}
+		v := reflect.New(t).Elem()
+		if integer.Value < 0 || v.OverflowUint(uint64(integer.Value)) {
+			return reflect.Value{}, fmt.Errorf("%d does not fit in %s", integer.Value, t)
+		}
+		v.SetUint(uint64(integer.Value))
+		return v, nil
+	case reflect.Float32, reflect.Float64:
+		// integers are fine where Go wants a float
+		v := reflect.New(t).Elem()
+		switch num := obj.(type) {
+		case *object.Float:
+			v.SetFloat(num.Value)
+			return v, nil
+		case *object.Integer:
+			v.SetFloat(float64(num.Value))
+			return v, nil
+		}
+	case reflect.String:
+		if str, ok := obj.(*object.String); ok {
+			return reflect.ValueOf(str.Value).Convert(t), nil
+		}
+	case reflect.Bool:
+		if b, ok := obj.(*object.Boolean); ok {
+			return reflect.ValueOf(b.Value).Convert(t), nil
+		}
+	case reflect.Slice:
+		arr, ok := obj.(*object.Array)
+		if !ok {
+			break
+		}
+		v := reflect.MakeSlice(t, len(arr.Elements), len(arr.Elements))
+		for i, el := range arr.Elements {
+			goEl, err := ToGoValue(el, t.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			v.Index(i).Set(goEl)
+		}
+		return v, nil
+	case reflect.Interface:
+		if t.NumMethod() != 0 {
+			break
+		}
+		v := reflect.New(t).Elem()
+		if goVal := naturalGoValue(obj); goVal != nil {
+			v.Set(reflect.ValueOf(goVal))
+		}
+		return v, nil
+	}
+	return reflect.Value{}, fmt.Errorf("cannot use %s as %s", obj.Type(), t)
+}
+
+// naturalGoValue is what obj looks like when Go doesn't ask for a type
+func naturalGoValue(obj object.Object) interface{} {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return obj.Value
+	case *object.Float:
+		return obj.Value
+	case *object.String:
+		return obj.Value
+	case *object.Boolean:
+		return obj.Value
+	case *object.Null:
+		return nil
+	case *object.Array:
+		elements := make([]interface{}, len(obj.Elements))
+		for i, el := range obj.Elements {
+			elements[i] = naturalGoValue(el)
+		}
+		return elements
+	case *object.Host:
+		return obj.Value
+	}
+	return obj
+}
+
+// objectTypeForGo is the type of lim object that converts to t, used for
+// error messages
+func objectTypeForGo(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return object.INTEGER_OBJ
+	case reflect.Float32, reflect.Float64:
+		return object.FLOAT_OBJ
+	case reflect.String:
+		return object.STRING_OBJ
+	case reflect.Bool:
+		return object.BOOLEAN_OBJ
+	case reflect.Slice:
+		return object.ARRAY_OBJ
+	case reflect.Func:
+		return object.FUNCTION_OBJ
+	}
+	return t.String()
+}
+
+// NewBuiltin wraps an ordinary Go function so scripts can call it. Arguments
+// are converted with ToGoValue and results with ToObject, a function may
+// return nothing, a value, an error or a value and an error. A non nil error
+// is turned into a lim error. name is used in error messages.
+func NewBuiltin(name string, fn interface{}) (*object.Builtin, error) {
+	fnVal := reflect.ValueOf(fn)
+	if fnVal.Kind() != reflect.Func {
+		return nil, fmt.Errorf("builtin %s must be a function, got %T", name, fn)
+	}
+	fnType := fnVal.Type()
+
+	switch fnType.NumOut() {
+	case 0:
+	case 1:
+	case 2:
+		if fnType.Out(1) != errorInterface {
+			return nil, fmt.Errorf("second result of builtin %s must be error, got %s", name, fnType.Out(1))
+		}
+	default:
+		return nil, fmt.Errorf("builtin %s returns too many values", name)
+	}
+
+	return &object.Builtin{
+		Name: name,
+		Fn: func(env *object.Environment, args ...object.Object) (result object.Object) {
+			in, errObj := builtinArguments(name, fnType, args)
+			if errObj != nil {
+				return errObj
+			}
+			// a lim function the builtin called through a func value
+			// without an error result failed
+			defer func() {
+				if r := recover(); r != nil {
+					cp, ok := r.(callbackPanic)
+					if !ok {
+						panic(r)
+					}
+					result = cp.err
+				}
+			}()
+			return builtinResult(fnVal.Call(in))
+		},
+	}, nil
+}
+
+func builtinArguments(name string, fnType reflect.Type, args []object.Object) ([]reflect.Value, *object.Error) {
+	numIn := fnType.NumIn()
+	if fnType.IsVariadic() {
+		if len(args) < numIn-1 {
+			return nil, newError("wrong number of arguments to `%s`. got=%d, want at least %d", name, len(args), numIn-1)
+		}
+	} else if len(args) != numIn {
+		return nil, newError("wrong number of arguments to `%s`. got=%d, want=%d", name, len(args), numIn)
+	}
+
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		if arg == nil {
+			arg = NULL
+		}
+		var paramType reflect.Type
+		if fnType.IsVariadic() && i >= numIn-1 {
+			paramType = fnType.In(numIn - 1).Elem()
+		} else {
+			paramType = fnType.In(i)
+		}
+		goVal, err := ToGoValue(arg, paramType)
+		if err != nil {
+			if want := objectTypeForGo(paramType); want != string(arg.Type()) {
+				return nil, newError("argument %d to `%s` must be %s, got %s", i+1, name, want, arg.Type())
+			}
+			return nil, newError("argument %d to `%s`: %s", i+1, name, err)
+		}
+		in[i] = goVal
+	}
+	return in, nil
+}
+
+func builtinResult(out []reflect.Value) object.Object {
+	if len(out) == 0 {
+		return NULL
+	}
+	if last := out[len(out)-1]; last.Type() == errorInterface {
+		if !last.IsNil() {
+			return newError("%s", last.Interface().(error))
+		}
+		out = out[:len(out)-1]
+		if len(out) == 0 {
+			return NULL
+		}
+	}
+	obj, err := toObject(out[0])
+	if err != nil {
+		return newError("%s", err)
+	}
+	return obj
+}
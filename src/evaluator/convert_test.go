@@ -0,0 +1,139 @@
+package evaluator
+
+import (
+	"errors"
+	"limLang/lexer"
+	"limLang/object"
+	"limLang/parser"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testEvalWith(input string, builtins map[string]interface{}) object.Object {
+	env := object.NewEnvironment()
+	for name, fn := range builtins {
+		builtin, err := NewBuiltin(name, fn)
+		if err != nil {
+			panic(err)
+		}
+		env.Set(name, builtin)
+	}
+	l := lexer.New(input)
+	p := parser.New(l)
+	return Eval(p.ParseProgram(), env)
+}
+
+func TestNewBuiltin(t *testing.T) {
+	builtins := map[string]interface{}{
+		"greet": func(name string, times int64) (string, error) {
+			if times < 0 {
+				return "", errors.New("times can't be negative")
+			}
+			return strings.Repeat("hi "+name+" ", int(times)), nil
+		},
+		"sum": func(nums ...int) int {
+			total := 0
+			for _, n := range nums {
+				total += n
+			}
+			return total
+		},
+		"small":   func(n int8) int8 { return n },
+		"not":     func(b bool) bool { return !b },
+		"lengths": func(strs []string) []int { return []int{len(strs[0]), len(strs[1])} },
+		"kind":    func(v interface{}) string { return reflect.TypeOf(v).String() },
+		"inspect": func(obj object.Object) string { return obj.Inspect() },
+		"nothing": func() {},
+	}
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`greet("lim", 2)`, "hi lim hi lim "},
+		{`greet("lim", -1)`, errors.New("times can't be negative")},
+		{`greet("lim")`, errors.New("wrong number of arguments to `greet`. got=1, want=2")},
+		{`greet(2, "lim")`, errors.New("argument 1 to `greet` must be STRING, got INTEGER")},
+		{`sum()`, 0},
+		{`sum(1, 2, 3)`, 6},
+		{`sum(1, true)`, errors.New("argument 2 to `sum` must be INTEGER, got BOOLEAN")},
+		{`small(100)`, 100},
+		{`small(300)`, errors.New("argument 1 to `small`: 300 does not fit in int8")},
+		{`not(true)`, false},
+		{`string []strs = ["ab", "cde"]; lengths(strs)`, "[2, 3]"},
+		{`kind(1)`, "int64"},
+		{`string []strs = ["ab"]; kind(strs)`, "[]interface {}"},
+		{`inspect(true)`, "true"},
+		{`nothing()`, nil},
+	}
+	for _, tt := range tests {
+		evaluated := testEvalWith(tt.input, builtins)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			if evaluated == nil || evaluated.Inspect() != expected {
+				t.Errorf("wrong result for %q. expected=%q, got=%v", tt.input, expected, evaluated)
+			}
+		case error:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error for %q. got=%T (%+v)", tt.input, evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected.Error() {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected.Error(), errObj.Message)
+			}
+		case nil:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestNewBuiltinRejectsBadFunctions(t *testing.T) {
+	tests := []interface{}{
+		5,
+		func() (int, int) { return 1, 2 },
+		func() (int, error, error) { return 1, nil, nil },
+	}
+	for _, fn := range tests {
+		if _, err := NewBuiltin("bad", fn); err == nil {
+			t.Errorf("expected an error for %T", fn)
+		}
+	}
+}
+
+func TestToObject(t *testing.T) {
+	tests := []struct {
+		input    interface{}
+		expected string
+	}{
+		{42, "42"},
+		{uint8(7), "7"},
+		{"str", "str"},
+		{true, "true"},
+		{[]int{1, 2}, "[1, 2]"},
+		{[]interface{}{1, "a", false}, "[1, a, false]"},
+		{nil, "null"},
+		{&object.Integer{Value: 3}, "3"},
+	}
+	for _, tt := range tests {
+		obj, err := ToObject(tt.input)
+		if err != nil {
+			t.Errorf("unexpected error for %v: %s", tt.input, err)
+			continue
+		}
+		if obj.Inspect() != tt.expected {
+			t.Errorf("wrong object for %v. expected=%q, got=%q", tt.input, tt.expected, obj.Inspect())
+		}
+	}
+
+	if _, err := ToObject(uint64(1 << 63)); err == nil {
+		t.Errorf("expected an error for a uint64 that doesn't fit")
+	}
+	if _, err := ToObject(make(chan int)); err == nil {
+		t.Errorf("expected an error for a channel")
+	}
+}
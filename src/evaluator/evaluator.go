@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"limLang/ast"
 	"limLang/object"
+	"limLang/token"
+	"math"
+	"strings"
 )
 
 var (
@@ -13,6 +16,10 @@ var (
 )
 
 func Eval(node ast.Node, env *object.Environment) object.Object {
+	if err := env.Runtime().Step(); err != nil {
+		return limitError(err)
+	}
+
 	switch node := node.(type) {
 	case *ast.Program:
 		return evalProgram(node, env)
@@ -25,27 +32,43 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if isError(right) {
 			return right
 		}
-		return evalPrefixExpression(node.Operator, right)
+		return withPosition(track(evalPrefixExpression(node.Operator, right, env), env), node.Token)
 
 	case *ast.InfixExpression:
 		left := Eval(node.Left, env)
 		if isError(left) {
 			return left
 		}
+		if node.Operator == "??" {
+			// the default is only evaluated when it is needed
+			if left != nil && left != NULL {
+				return left
+			}
+			return Eval(node.Right, env)
+		}
 		right := Eval(node.Right, env)
 		if isError(right) {
 			return right
 		}
-		return evalInfixExpression(node.Operator, left, right)
+		return withPosition(track(evalInfixExpression(node.Operator, left, right, env), env), node.Token)
 
 	case *ast.IntegerLiteral:
-		return &object.Integer{Value: node.Value}
+		return track(&object.Integer{Value: node.Value}, env)
+
+	case *ast.FloatLiteral:
+		return track(&object.Float{Value: node.Value}, env)
 
 	case *ast.Boolean:
 		return nativeBoolToBooleanObject(node.Value)
 
+	case *ast.Null:
+		return NULL
+
 	case *ast.StringVal:
-		return &object.String{Value: node.Value}
+		return track(&object.String{Value: node.Value}, env)
+
+	case *ast.InterpolatedString:
+		return evalInterpolatedString(node, env)
 
 	case *ast.BlockStatement:
 		return evalBlockStatement(node, env)
@@ -63,7 +86,7 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	case *ast.FunctionStatement:
 		params := node.Parameters
 		body := node.Body
-		fnObj := &object.Function{Parameters: params, Env: env, Body: body}
+		fnObj := &object.Function{Parameters: params, Env: env, Body: body, FuncName: node.FnName}
 		env.Set(node.FnName, fnObj)
 
 	case *ast.CallExpression:
@@ -75,38 +98,42 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if len(args) == 1 && isError(args[0]) {
 			return args[0]
 		}
-		return applyFunction(function, args)
+		return applyFunction(function, args, node.Token, env)
 
 	case *ast.IntStatement:
-		val := Eval(node.Value, env)
-		if isError(val) {
-			return val
-		}
-		env.Set(node.Name.Value, val)
+		return evalTypedStatement(node.Name, node.Value, object.INTEGER_OBJ, node.Nullable, node.Const, env)
+
+	case *ast.FloatStatement:
+		return evalTypedStatement(node.Name, node.Value, object.FLOAT_OBJ, node.Nullable, node.Const, env)
 
 	case *ast.BoolStatement:
-		val := Eval(node.Value, env)
-		if isError(val) {
-			return val
-		}
-		env.Set(node.Name.Value, val)
+		return evalTypedStatement(node.Name, node.Value, object.BOOLEAN_OBJ, node.Nullable, node.Const, env)
 
 	case *ast.StringStatement:
-		val := Eval(node.Value, env)
-		if isError(val) {
-			return val
-		}
-		env.Set(node.Name.Value, val)
+		return evalTypedStatement(node.Name, node.Value, object.STRING_OBJ, node.Nullable, node.Const, env)
+
+	case *ast.ReassignStatement:
+		return evalReassignStatement(node, env)
 
 	case *ast.ArrayLiteral:
-		elements := evalExpressions(node.Elements, env)
-		if len(elements) == 1 && isError(elements[0]) {
-			return elements[0]
+		elements, errObj := evalArrayElements(node, env)
+		if errObj != nil {
+			return errObj
+		}
+		if want, ok := objectTypeOf(node.Type.Type); ok {
+			for i, el := range elements {
+				if el.Type() != want {
+					return newErrorAt(node.Name.Token, "type mismatch: cannot use %s as element %d of `%s` of type %s", el.Type(), i, node.Name.Value, want)
+				}
+			}
 		}
 		// this is where the problem is i think
 		// rn i am not saving array in env
 		arrElems := &object.Array{Elements: elements}
-		env.Set(node.Name.Value, arrElems)
+		if errObj := track(arrElems, env); isError(errObj) {
+			return errObj
+		}
+		env.Declare(node.Name.Value, arrElems, object.ARRAY_OBJ)
 		// return &object.Array{Elements: elements}
 
 	case *ast.IndexExpression:
@@ -116,18 +143,41 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		}
 		elems, ok := ident.(*object.Array)
 		if !ok {
-			return elems
+			return newErrorAt(node.Token, "index operator not supported: %s", typeOf(ident))
 		}
 
 		index := Eval(node.Index, env)
 		if isError(index) {
 			return index
 		}
-		fmt.Println("wtf : ", elems.Elements[0], index)
 		return evalIndexExpression(elems, index)
 	case *ast.Identifier:
 		return evalIdentifier(node, env)
 
+	case *ast.MemberExpression:
+		obj := Eval(node.Object, env)
+		if isError(obj) {
+			return obj
+		}
+		if node.Optional && (obj == nil || obj == NULL) {
+			return NULL
+		}
+		return withPosition(evalMemberExpression(obj, node.Property.Value), node.Property.Token)
+
+	case *ast.MemberAssignStatement:
+		obj := Eval(node.Target.Object, env)
+		if isError(obj) {
+			return obj
+		}
+		val := Eval(node.Value, env)
+		if isError(val) {
+			return val
+		}
+		if val == nil {
+			val = NULL
+		}
+		return withPosition(evalMemberAssignment(obj, node.Target.Property.Value, val), node.Target.Property.Token)
+
 	}
 	return nil
 }
@@ -147,6 +197,81 @@ func evalProgram(program *ast.Program, env *object.Environment) object.Object {
 	return result
 }
 
+// evalArrayElements evaluates the elements of an array declaration, they
+// are either listed or come from an expression that gives an array
+func evalArrayElements(node *ast.ArrayLiteral, env *object.Environment) ([]object.Object, object.Object) {
+	if node.Value == nil {
+		elements := evalExpressions(node.Elements, env)
+		if len(elements) == 1 && isError(elements[0]) {
+			return nil, elements[0]
+		}
+		return elements, nil
+	}
+
+	val := Eval(node.Value, env)
+	if isError(val) {
+		return nil, val
+	}
+	arr, ok := val.(*object.Array)
+	if !ok {
+		return nil, newErrorAt(node.Name.Token, "type mismatch: cannot assign %s to `%s` of type %s", typeOf(val), node.Name.Value, object.ARRAY_OBJ)
+	}
+	return arr.Elements, nil
+}
+
+// evalTypedStatement evaluates declarations like `int x = 5`, the variable
+// is created with the declared type so later reassignments are checked too.
+// Nullable variables (`int? x`) also take null, constants can't be assigned
+// again.
+func evalTypedStatement(name *ast.Identifier, value ast.Expression, want object.ObjectType, nullable, constant bool, env *object.Environment) object.Object {
+	val := Eval(value, env)
+	if isError(val) {
+		return val
+	}
+	if val == nil {
+		val = NULL
+	}
+	if !nullable || val != NULL {
+		val = widen(val, want)
+		if val.Type() != want {
+			return newErrorAt(name.Token, "type mismatch: cannot assign %s to `%s` of type %s", val.Type(), name.Value, want)
+		}
+	}
+	if nullable {
+		env.DeclareNullable(name.Value, val, want)
+	} else {
+		env.Declare(name.Value, val, want)
+	}
+	if constant {
+		env.MakeConstant(name.Value)
+	}
+	return nil
+}
+
+func evalReassignStatement(node *ast.ReassignStatement, env *object.Environment) object.Object {
+	val := Eval(node.Value, env)
+	if isError(val) {
+		return val
+	}
+	if val == nil {
+		val = NULL
+	}
+	if env.Constant(node.Name.Value) {
+		return newErrorAt(node.Name.Token, "cannot assign to `%s`, it is a constant", node.Name.Value)
+	}
+	want, typed := env.TypeOf(node.Name.Value)
+	if typed {
+		val = widen(val, want)
+	}
+	if typed && val.Type() != want && !(val == NULL && env.Nullable(node.Name.Value)) {
+		return newErrorAt(node.Name.Token, "type mismatch: cannot assign %s to `%s` of type %s", val.Type(), node.Name.Value, want)
+	}
+	if !env.Assign(node.Name.Value, val) {
+		return newErrorAt(node.Name.Token, "identifier not found: "+node.Name.Value)
+	}
+	return nil
+}
+
 func evalIndexExpression(ident, index object.Object) object.Object {
 	switch {
 	case ident.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
@@ -181,7 +306,10 @@ func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Ob
 	return result
 }
 
-func applyFunction(fn object.Object, args []object.Object) object.Object {
+// applyFunction calls fn with args from env. callSite is the token of the
+// call, when the function fails it is recorded on the error so we can print a
+// traceback.
+func applyFunction(fn object.Object, args []object.Object, callSite token.Token, env *object.Environment) object.Object {
 	// function, ok := fn.(*object.Function)
 	// if !ok {
 	// 	return newError("Not a function: %s", fn.Type())
@@ -191,25 +319,129 @@ func applyFunction(fn object.Object, args []object.Object) object.Object {
 	// return unwrapReturnValue(evaluated)
 	switch fn := fn.(type) {
 	case *object.Function:
-		extendedEnv := extendedFunctionEnv(fn, args)
-		evaluated := Eval(fn.Body, extendedEnv)
-		return unwrapReturnValue(evaluated)
+		var evaluated object.Object
+		rt := fn.Env.Runtime()
+		if err := rt.EnterCall(); err != nil {
+			evaluated = limitError(err)
+		} else {
+			evaluated = evalFunctionBody(fn, args)
+			rt.LeaveCall()
+		}
+		if errObj, ok := evaluated.(*object.Error); ok {
+			errObj.Stack = append(errObj.Stack, object.StackFrame{FuncName: fn.FuncName, Line: callSite.Line, Column: callSite.Column})
+		}
+		return evaluated
 
 	case *object.Builtin:
-		return fn.Fn(args...)
+		if errObj := checkCapabilities(fn, env); errObj != nil {
+			return errObj
+		}
+		return track(fn.Fn(env, args...), env)
 
 	default:
 		return newError("Not a function: %s", fn.Type())
 	}
 }
 
-func extendedFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
+func evalFunctionBody(fn *object.Function, args []object.Object) object.Object {
+	if err := fn.Env.Runtime().Allocate(environmentSize); err != nil {
+		return limitError(err)
+	}
+	extendedEnv, errObj := extendedFunctionEnv(fn, args)
+	if errObj != nil {
+		return errObj
+	}
+	return unwrapReturnValue(Eval(fn.Body, extendedEnv))
+}
+
+// CallFunction calls a lim function or builtin from Go, env is used by
+// builtins to find the interpreter they belong to.
+func CallFunction(fn object.Object, env *object.Environment, args ...object.Object) object.Object {
+	return applyFunction(fn, args, token.Token{}, env)
+}
+
+// extendedFunctionEnv binds args to the parameters of fn. The number of
+// arguments has to match the parameters (a variadic parameter takes any
+// number of trailing arguments as an array) and typed parameters only accept
+// values of their type.
+func extendedFunctionEnv(fn *object.Function, args []object.Object) (*object.Environment, *object.Error) {
 	env := object.NewEnclosedEnviornment(fn.Env)
 
-	for paramIdx, param := range fn.Parameters {
-		env.Set(param.Value, args[paramIdx])
+	params := fn.Parameters
+	variadic := len(params) > 0 && params[len(params)-1].Variadic
+	if variadic {
+		params = params[:len(params)-1]
+		if len(args) < len(params) {
+			return nil, newError("wrong number of arguments to `%s`. got=%d, want at least %d", fn.FuncName, len(args), len(params))
+		}
+	} else if len(args) != len(params) {
+		return nil, newError("wrong number of arguments to `%s`. got=%d, want=%d", fn.FuncName, len(args), len(params))
+	}
+
+	for paramIdx, param := range params {
+		arg, errObj := checkArgumentType(fn, param, args[paramIdx])
+		if errObj != nil {
+			return nil, errObj
+		}
+		if want, ok := objectTypeOf(param.HoldsVarType.Type); ok && param.Nullable {
+			env.DeclareNullable(param.Value, arg, want)
+		} else if ok {
+			env.Declare(param.Value, arg, want)
+		} else {
+			env.Set(param.Value, arg)
+		}
+	}
+
+	if variadic {
+		param := fn.Parameters[len(fn.Parameters)-1]
+		rest := make([]object.Object, 0, len(args)-len(params))
+		for _, arg := range args[len(params):] {
+			arg, errObj := checkArgumentType(fn, param, arg)
+			if errObj != nil {
+				return nil, errObj
+			}
+			rest = append(rest, arg)
+		}
+		env.Declare(param.Value, &object.Array{Elements: rest}, object.ARRAY_OBJ)
 	}
-	return env
+	return env, nil
+}
+
+// checkArgumentType gives arg the way param holds it, integers passed for
+// float parameters become floats
+func checkArgumentType(fn *object.Function, param *ast.Identifier, arg object.Object) (object.Object, *object.Error) {
+	want, ok := objectTypeOf(param.HoldsVarType.Type)
+	if !ok || (param.Nullable && arg == NULL) {
+		return arg, nil
+	}
+	if arg = widen(arg, want); arg.Type() == want {
+		return arg, nil
+	}
+	return nil, newError("argument `%s` to `%s` must be %s, got %s", param.Value, fn.FuncName, want, arg.Type())
+}
+
+// widen converts an integer to a float when a float is wanted, like the
+// math builtins do. Anything else is given back as it is.
+func widen(val object.Object, want object.ObjectType) object.Object {
+	if want == object.FLOAT_OBJ && val.Type() == object.INTEGER_OBJ {
+		return toFloat(val)
+	}
+	return val
+}
+
+// objectTypeOf maps a type keyword like `int` to the type of object it holds
+func objectTypeOf(t token.TokenType) (object.ObjectType, bool) {
+	switch t {
+	case token.Keyword_INT:
+		return object.INTEGER_OBJ, true
+	case token.Keyword_FLOAT:
+		return object.FLOAT_OBJ, true
+	case token.Keyword_BOOL:
+		return object.BOOLEAN_OBJ, true
+	case token.Keyword_STRING:
+		return object.STRING_OBJ, true
+	}
+	return "", false
 }
 
 func unwrapReturnValue(obj object.Object) object.Object {
@@ -244,50 +476,192 @@ func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object
 	if builtin, ok := buildtins[node.Value]; ok {
 		return builtin
 	}
+	if module, ok := modules[node.Value]; ok {
+		return module
+	}
 	return newError("identifier not found: " + node.Value)
 }
 
-func evalInfixExpression(operator string, left, right object.Object) object.Object {
+func evalInfixExpression(operator string, left, right object.Object, env *object.Environment) object.Object {
+	// functions that don't return anything give nil
+	left, right = orNull(left), orNull(right)
 	switch {
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
-		return evalIntegerInfixExpression(operator, left, right)
-	case operator == "==":
-		return nativeBoolToBooleanObject(left == right)
-	case operator == "!=":
-		return nativeBoolToBooleanObject(left != right)
+		return evalIntegerInfixExpression(operator, left, right, env.Runtime().CheckedArithmetic)
+	case isNumber(left) && isNumber(right):
+		// one of them is a float, integers are converted to float
+		return evalFloatInfixExpression(operator, toFloat(left), toFloat(right))
+	case operator == "==" || operator == "!=":
+		return evalEqualityExpression(operator, left, right)
 	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
 		return evalStringInfixExpression(operator, left, right)
+	case left.Type() == object.ARRAY_OBJ && right.Type() == object.ARRAY_OBJ:
+		return evalOrderingExpression(operator, left, right)
 	case left.Type() != right.Type():
 		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
 	default:
 		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
 }
-func evalStringInfixExpression(operator string, left, right object.Object) object.Object {
-	if operator != "+" {
+
+// evalEqualityExpression compares values, see objectsEqual. Anything can be
+// compared with null, otherwise both sides must have the same type.
+func evalEqualityExpression(operator string, left, right object.Object) object.Object {
+	if left != NULL && right != NULL && left.Type() != right.Type() {
+		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
+	}
+	equal := objectsEqual(left, right)
+	if operator == "!=" {
+		return nativeBoolToBooleanObject(!equal)
+	}
+	return nativeBoolToBooleanObject(equal)
+}
+
+// evalOrderingExpression evaluates < <= > >= for strings and arrays, see
+// compareObjects
+func evalOrderingExpression(operator string, left, right object.Object) object.Object {
+	switch operator {
+	case "<", "<=", ">", ">=":
+	default:
 		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
+	cmp, errObj := compareObjects(operator, left, right)
+	if errObj != nil {
+		return errObj
+	}
+	switch operator {
+	case "<":
+		return nativeBoolToBooleanObject(cmp < 0)
+	case "<=":
+		return nativeBoolToBooleanObject(cmp <= 0)
+	case ">":
+		return nativeBoolToBooleanObject(cmp > 0)
+	default:
+		return nativeBoolToBooleanObject(cmp >= 0)
+	}
+}
+
+// evalInterpolatedString puts the values of the expressions in the string,
+// strings as they are and everything else the way print shows it
+func evalInterpolatedString(node *ast.InterpolatedString, env *object.Environment) object.Object {
+	var out strings.Builder
+	for _, part := range node.Parts {
+		val := Eval(part, env)
+		if isError(val) {
+			return val
+		}
+		if str, ok := val.(*object.String); ok {
+			out.WriteString(str.Value)
+		} else {
+			out.WriteString(orNull(val).Inspect())
+		}
+	}
+	return track(&object.String{Value: out.String()}, env)
+}
+
+// strings are ordered byte by byte, which for UTF-8 is the order of their
+// code points
+func evalStringInfixExpression(operator string, left, right object.Object) object.Object {
 	leftVal := left.(*object.String).Value
 	rightVal := right.(*object.String).Value
-	return &object.String{Value: leftVal + rightVal}
+	switch operator {
+	case "+":
+		return &object.String{Value: leftVal + rightVal}
+	default:
+		return evalOrderingExpression(operator, left, right)
+	}
 }
 
-func evalIntegerInfixExpression(operator string, left, right object.Object) object.Object {
+func evalFloatInfixExpression(operator string, left, right *object.Float) object.Object {
+	leftVal, rightVal := left.Value, right.Value
+	switch operator {
+	case "+":
+		return &object.Float{Value: leftVal + rightVal}
+	case "-":
+		return &object.Float{Value: leftVal - rightVal}
+	case "*":
+		return &object.Float{Value: leftVal * rightVal}
+	case "/":
+		if rightVal == 0 {
+			return newError("division by zero: %s / %s", left.Inspect(), right.Inspect())
+		}
+		return &object.Float{Value: leftVal / rightVal}
+	case "%":
+		if rightVal == 0 {
+			return newError("modulo by zero: %s %% %s", left.Inspect(), right.Inspect())
+		}
+		return &object.Float{Value: math.Mod(leftVal, rightVal)}
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func isNumber(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.FLOAT_OBJ
+}
+
+func toFloat(obj object.Object) *object.Float {
+	if integer, ok := obj.(*object.Integer); ok {
+		return &object.Float{Value: float64(integer.Value)}
+	}
+	return obj.(*object.Float)
+}
+
+func evalIntegerInfixExpression(operator string, left, right object.Object, checked bool) object.Object {
 	leftVal := left.(*object.Integer).Value
 	rightVal := right.(*object.Integer).Value
 	switch operator {
 	case "+":
-		return &object.Integer{Value: leftVal + rightVal}
+		result := leftVal + rightVal
+		if checked && (leftVal^result)&(rightVal^result) < 0 {
+			return newError("integer overflow: %d + %d", leftVal, rightVal)
+		}
+		return &object.Integer{Value: result}
 	case "-":
-		return &object.Integer{Value: leftVal - rightVal}
+		result := leftVal - rightVal
+		if checked && (leftVal^rightVal)&(leftVal^result) < 0 {
+			return newError("integer overflow: %d - %d", leftVal, rightVal)
+		}
+		return &object.Integer{Value: result}
 	case "*":
-		return &object.Integer{Value: leftVal * rightVal}
+		result := leftVal * rightVal
+		if checked && leftVal != 0 && (result/leftVal != rightVal || (leftVal == -1 && rightVal == math.MinInt64)) {
+			return newError("integer overflow: %d * %d", leftVal, rightVal)
+		}
+		return &object.Integer{Value: result}
 	case "/":
+		if rightVal == 0 {
+			return newError("division by zero: %d / %d", leftVal, rightVal)
+		}
+		if checked && leftVal == math.MinInt64 && rightVal == -1 {
+			return newError("integer overflow: %d / %d", leftVal, rightVal)
+		}
 		return &object.Integer{Value: leftVal / rightVal}
+	case "%":
+		if rightVal == 0 {
+			return newError("modulo by zero: %d %% %d", leftVal, rightVal)
+		}
+		return &object.Integer{Value: leftVal % rightVal}
 	case "<":
 		return nativeBoolToBooleanObject(leftVal < rightVal)
 	case ">":
 		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
 	case "==":
 		return nativeBoolToBooleanObject(leftVal == rightVal)
 	case "!=":
@@ -297,12 +671,12 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 	}
 }
 
-func evalPrefixExpression(operator string, right object.Object) object.Object {
+func evalPrefixExpression(operator string, right object.Object, env *object.Environment) object.Object {
 	switch operator {
 	case "!":
 		return evalBangOperatorExpression(right)
 	case "-":
-		return evalMinusPrefixOperatorExpression(right)
+		return evalMinusPrefixOperatorExpression(right, env.Runtime().CheckedArithmetic)
 	default:
 		return newError("unknown operator: %s%s", operator, right.Type())
 	}
@@ -320,11 +694,17 @@ func evalBangOperatorExpression(right object.Object) object.Object {
 		return FALSE
 	}
 }
-func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
+func evalMinusPrefixOperatorExpression(right object.Object, checked bool) object.Object {
+	if float, ok := right.(*object.Float); ok {
+		return &object.Float{Value: -float.Value}
+	}
 	if right.Type() != object.INTEGER_OBJ {
 		return newError("unknown operator: %s", right.Type())
 	}
 	value := right.(*object.Integer).Value
+	if checked && value == math.MinInt64 {
+		return newError("integer overflow: -(%d)", value)
+	}
 	return &object.Integer{Value: -value}
 }
 
@@ -371,6 +751,22 @@ func newError(format string, a ...interface{}) *object.Error {
 	return &object.Error{Message: fmt.Sprintf(format, a...)}
 }
 
+// newErrorAt is newError for errors that we can point at a place in the source
+func newErrorAt(tok token.Token, format string, a ...interface{}) *object.Error {
+	errObj := newError(format, a...)
+	errObj.Line, errObj.Column = tok.Line, tok.Column
+	return errObj
+}
+
+// withPosition points obj at tok if it is an error that doesn't know where
+// it happened yet
+func withPosition(obj object.Object, tok token.Token) object.Object {
+	if errObj, ok := obj.(*object.Error); ok && errObj.Line == 0 {
+		errObj.Line, errObj.Column = tok.Line, tok.Column
+	}
+	return obj
+}
+
 func isError(obj object.Object) bool {
 	if obj != nil {
 		return obj.Type() == object.ERROR_OBJ
@@ -28,6 +28,9 @@ func TestEvalIntegerExpression(t *testing.T) {
 		{"3 * 3 * 3 + 10", 37},
 		{"3 * (3 * 3) + 10", 37},
 		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+		{"0xFF + 0o17 + 0b1010", 280},
+		{"1_000 * 1_000", 1000000},
+		{"-9223372036854775808", -9223372036854775808},
 	}
 
 	for _, tt := range tests {
@@ -70,6 +73,12 @@ func TestEvalBooleanExpression(t *testing.T) {
 		{"1 > 2", false},
 		{"1 < 1", false},
 		{"1 > 1", false},
+		{"1 <= 1", true},
+		{"2 <= 1", false},
+		{"1 >= 1", true},
+		{"1 >= 2", false},
+		{"1.5 <= 2", true},
+		{"2 >= 2.5", false},
 		{"1 == 1", true},
 		{"1 != 1", false},
 		{"1 == 2", false},
@@ -257,7 +266,7 @@ func TestBooleanStatements(t *testing.T) {
 	}{
 		{"bool a = true; a;", true},
 		{"bool a = false; a;", false},
-		{"bool a = true; int b = !a; b;", false},
+		{"bool a = true; bool b = !a; b;", false},
 		{"bool a = true; bool b = false; bool c = a != b ; c;", true},
 	}
 	for _, tt := range tests {
@@ -379,7 +388,8 @@ func TestBuiltinFunctions(t *testing.T) {
 }
 
 func TestArrayLiterals(t *testing.T) {
-	input := "int []data = [1, 2 * 2, 3 + 3]"
+	// a declaration has no value of its own, the array is read back
+	input := "int []data = [1, 2 * 2, 3 + 3]; data"
 	evaluated := testEval(input)
 	result, ok := evaluated.(*object.Array)
 	if !ok {
@@ -470,3 +480,721 @@ func TestPrintFunction(t *testing.T) {
 		fmt.Println(evaluated)
 	}
 }
+
+func TestErrorStackTrace(t *testing.T) {
+	input := `fn inner(int a) {
+		return a + x
+	}
+	fn outer(int a) {
+		inner(a)
+	}
+	outer(1)`
+	evaluated := testEval(input)
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T (%+v)", evaluated, evaluated)
+	}
+	expected := []object.StackFrame{
+		{FuncName: "inner", Line: 5, Column: 8},
+		{FuncName: "outer", Line: 7, Column: 7},
+	}
+	if len(errObj.Stack) != len(expected) {
+		t.Fatalf("wrong number of stack frames. expected=%d, got=%d", len(expected), len(errObj.Stack))
+	}
+	for i, frame := range expected {
+		if errObj.Stack[i] != frame {
+			t.Errorf("stack[%d] wrong. expected=%+v, got=%+v", i, frame, errObj.Stack[i])
+		}
+	}
+	traceback := "error: identifier not found: x\n    at inner (line 5, column 8)\n    at outer (line 7, column 7)"
+	if errObj.Traceback() != traceback {
+		t.Errorf("wrong traceback. expected=%q, got=%q", traceback, errObj.Traceback())
+	}
+}
+
+func TestFunctionArguments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"fn add (int x, int y) { x + y } add(1)", "wrong number of arguments to `add`. got=1, want=2"},
+		{"fn add (int x, int y) { x + y } add(1, 2, 3)", "wrong number of arguments to `add`. got=3, want=2"},
+		{`fn add (int x, int y) { x + y } add(1, "2")`, "argument `y` to `add` must be INTEGER, got STRING"},
+		{`fn not (bool b) { !b } not(1)`, "argument `b` to `not` must be BOOLEAN, got INTEGER"},
+		{"fn count (int ...nums) { len(nums) } count()", 0},
+		{"fn count (int ...nums) { len(nums) } count(1, 2, 3)", 3},
+		{"fn second (int x, int ...nums) { nums[1] } second(1, 2, 3)", 3},
+		{"fn first (int x, int ...nums) { x } first()", "wrong number of arguments to `first`. got=0, want at least 1"},
+		{`fn count (int ...nums) { len(nums) } count(1, true)`, "argument `nums` to `count` must be INTEGER, got BOOLEAN"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		}
+	}
+}
+
+func TestTypedDeclarations(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+		expectedLine    int
+		expectedColumn  int
+	}{
+		{`int x = "hello"`, "type mismatch: cannot assign STRING to `x` of type INTEGER", 1, 5},
+		{`bool b = 1`, "type mismatch: cannot assign INTEGER to `b` of type BOOLEAN", 1, 6},
+		{`string s = true`, "type mismatch: cannot assign BOOLEAN to `s` of type STRING", 1, 8},
+		{"int x = 1\nx = false", "type mismatch: cannot assign BOOLEAN to `x` of type INTEGER", 2, 1},
+		{"int []arr = [1, true]", "type mismatch: cannot use BOOLEAN as element 1 of `arr` of type INTEGER", 1, 7},
+		{"fn f(int a) { a = \"str\" } f(1)", "type mismatch: cannot assign STRING to `a` of type INTEGER", 1, 15},
+		{"y = 5", "identifier not found: y", 1, 1},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %q. got=%T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expectedMessage, errObj.Message)
+		}
+		if errObj.Line != tt.expectedLine || errObj.Column != tt.expectedColumn {
+			t.Errorf("wrong error position for %q. expected=%d:%d, got=%d:%d",
+				tt.input, tt.expectedLine, tt.expectedColumn, errObj.Line, errObj.Column)
+		}
+	}
+}
+
+func TestReassignStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"int a = 5; a = 10; a", 10},
+		{"int a = 5; a = a * 2 + 1; a", 11},
+		{"int a = 5; fn inc() { a = a + 1 } inc(); inc(); a", 7},
+		{"a := 3; a = 4; a", 4},
+		{"pub const int a = 5; a + 1", 6},
+		{"const int a = 5; int a = 6; a = 7; a", 7},
+	}
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+
+	errors := []string{
+		"const int a = 5; a = 6",
+		"const int a = 5; fn f() { a = 6 } f()",
+	}
+	for _, input := range errors {
+		errObj, ok := testEval(input).(*object.Error)
+		if !ok || errObj.Message != "cannot assign to `a`, it is a constant" {
+			t.Errorf("%s: expected the constant error. got=%v", input, testEval(input))
+		}
+	}
+}
+
+func TestIntegerArithmeticErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		checked  bool
+		expected interface{}
+	}{
+		{"int a = 0; 1 / a", false, "division by zero: 1 / 0"},
+		{"int a = 0; 1 % a", false, "modulo by zero: 1 % 0"},
+		{"7 % 3", false, 1},
+		{"-7 % 3", false, -1},
+		{"9223372036854775807 + 1", false, -9223372036854775808},
+		{"9223372036854775807 + 1", true, "integer overflow: 9223372036854775807 + 1"},
+		{"-9223372036854775807 - 2", true, "integer overflow: -9223372036854775807 - 2"},
+		{"4611686018427387904 * 2", true, "integer overflow: 4611686018427387904 * 2"},
+		{"4611686018427387904 * -2", true, -9223372036854775808},
+		{"int min = -9223372036854775807 - 1; -min", true, "integer overflow: -(-9223372036854775808)"},
+		{"int min = -9223372036854775807 - 1; min / -1", true, "integer overflow: -9223372036854775808 / -1"},
+		{"int min = -9223372036854775807 - 1; -min", false, -9223372036854775808},
+		{"4000000000 * 4000000000", true, "integer overflow: 4000000000 * 4000000000"},
+		{"5 * 5 - 3", true, 22},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		env := object.NewEnvironment()
+		env.Runtime().CheckedArithmetic = tt.checked
+		evaluated := Eval(p.ParseProgram(), env)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error for %q. got=%T (%+v)", tt.input, evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+			if errObj.Line == 0 {
+				t.Errorf("error for %q has no position", tt.input)
+			}
+		}
+	}
+}
+
+func TestCollectionBuiltins(t *testing.T) {
+	setup := `int []a = [1, 2, 3]; string []s = ["x", "y"]; int []empty = []; `
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"push(a, 4)", "[1, 2, 3, 4]"},
+		{"push(a, 4); a", "[1, 2, 3]"},
+		{"pop(a)", "[1, 2]"},
+		{"pop(empty)", "null"},
+		{"first(a)", "1"},
+		{"last(a)", "3"},
+		{"first(empty)", "null"},
+		{"rest(a)", "[2, 3]"},
+		{"rest(empty)", "null"},
+		{"concat(a, empty, a)", "[1, 2, 3, 1, 2, 3]"},
+		{"reverse(a)", "[3, 2, 1]"},
+		{"contains(a, 2)", "true"},
+		{"contains(a, 5)", "false"},
+		{`contains(s, "y")`, "true"},
+		{`index_of(s, "y")`, "1"},
+		{"index_of(a, 9)", "-1"},
+		{`join(s, ", ")`, "x, y"},
+		{`join(a, "-")`, "1-2-3"},
+		{"a = push(a, 4); len(a)", "4"},
+		{"int []b = rest(a); b", "[2, 3]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(setup + tt.input)
+		if evaluated == nil {
+			t.Errorf("%s: got nil", tt.input)
+			continue
+		}
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: wrong result. got=%q, want=%q", tt.input, evaluated.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestHigherOrderBuiltins(t *testing.T) {
+	setup := `
+	int []a = [3, 1, 2]
+	string []words = ["pear", "fig", "apple"]
+	fn double(int n) int { return n * 2 }
+	fn isOdd(int n) bool { return n % 2 == 1 }
+	fn add(int acc, int n) int { return acc + n }
+	fn size(string w) int { return len(w) }
+	fn self(string w) string { return w }
+	`
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"map(a, double)", "[6, 2, 4]"},
+		{"filter(a, isOdd)", "[3, 1]"},
+		{"reduce(a, add, 10)", "16"},
+		{"any(a, isOdd)", "true"},
+		{"all(a, isOdd)", "false"},
+		{"find(a, isOdd)", "3"},
+		{"find(map(a, double), isOdd)", "null"},
+		{"sort_by(a, double)", "[1, 2, 3]"},
+		{"sort_by(words, size)", "[fig, pear, apple]"},
+		{"sort_by(words, self)", "[apple, fig, pear]"},
+		{"map(words, len)", "[4, 3, 5]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(setup + tt.input)
+		if evaluated == nil {
+			t.Errorf("%s: got nil", tt.input)
+			continue
+		}
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: wrong result. got=%q, want=%q", tt.input, evaluated.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestCollectionBuiltinErrors(t *testing.T) {
+	setup := `
+	int []a = [1, 0, 2]
+	fn inverse(int n) int { return 10 / n }
+	fn ok(int n) bool { return true }
+	fn mixed(int n) { if (n == 0) { return "zero" } return n }
+	`
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"map(a, inverse)", "division by zero: 10 / 0"},
+		{"filter(a, inverse)", "division by zero: 10 / 0"},
+		{"reduce(a, inverse, 0)", "wrong number of arguments to `inverse`. got=2, want=1"},
+		{"map(a, 1)", "argument 2 to `map` must be FUNCTION, got INTEGER"},
+		{"push(1, 2)", "argument 1 to `push` must be ARRAY, got INTEGER"},
+		{"join(a, 1)", "argument 2 to `join` must be STRING, got INTEGER"},
+		{"sort_by(a, ok)", "keys of `sort_by` must be INTEGER or STRING, got BOOLEAN"},
+		{"sort_by(a, mixed)", "keys of `sort_by` must all have the same type, got INTEGER and STRING"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(setup + tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("%s: expected an error. got=%T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: wrong error message. got=%q, want=%q", tt.input, errObj.Message, tt.expected)
+		}
+	}
+
+	errObj, ok := testEval(setup + "map(a, inverse)").(*object.Error)
+	if !ok || len(errObj.Stack) != 1 || errObj.Stack[0].FuncName != "inverse" {
+		t.Errorf("callback errors should keep their traceback. got=%+v", errObj)
+	}
+}
+
+func TestStringsModule(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`strings.split("a,b,c", ",")`, "[a, b, c]"},
+		{`string []parts = strings.split("a b", " "); strings.join(parts, "-")`, "a-b"},
+		{`strings.trim("  hi  ")`, "hi"},
+		{`strings.trim_left("  hi  ")`, "hi  "},
+		{`strings.trim_right("  hi  ")`, "  hi"},
+		{`strings.trim_prefix("unhappy", "un")`, "happy"},
+		{`strings.trim_suffix("file.lim", ".lim")`, "file"},
+		{`strings.to_upper("lim")`, "LIM"},
+		{`strings.to_lower("LIM")`, "lim"},
+		{`strings.contains("seafood", "foo")`, "true"},
+		{`strings.starts_with("seafood", "sea")`, "true"},
+		{`strings.ends_with("seafood", "sea")`, "false"},
+		{`strings.replace("a-b-c", "-", "+")`, "a+b+c"},
+		{`strings.repeat("ab", 3)`, "ababab"},
+		{`strings.index("hello", "llo")`, "2"},
+		{`strings.index("hello", "x")`, "-1"},
+		{`strings.length("hello")`, "5"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil {
+			t.Errorf("%s: got nil", tt.input)
+			continue
+		}
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: wrong result. got=%q, want=%q", tt.input, evaluated.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestStringsModuleCountsRunes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{`strings.length(word)`, 5},
+		{`strings.index(word, "llo")`, 2},
+		{`len(word)`, 6},
+	}
+
+	for _, tt := range tests {
+		env := object.NewEnvironment()
+		env.Set("word", &object.String{Value: "héllo"})
+		program := parser.New(lexer.New(tt.input)).ParseProgram()
+		testIntegerObject(t, Eval(program, env), tt.expected)
+	}
+}
+
+func TestStringsModuleErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`strings.upper("a")`, "module `strings` has no member `upper`"},
+		{`strings.to_upper(1)`, "argument 1 to `strings.to_upper` must be STRING, got INTEGER"},
+		{`strings.split("a")`, "wrong number of arguments to `strings.split`. got=1, want=2"},
+		{`strings.repeat("a", -1)`, "argument 2 to `strings.repeat` must not be negative, got -1"},
+		{`strings.repeat("ab", 9223372036854775807)`, "`strings.repeat` result is too long: 9223372036854775807 * 2 bytes"},
+	}
+
+	for _, tt := range tests {
+		errObj, ok := testEval(tt.input).(*object.Error)
+		if !ok {
+			t.Errorf("%s: expected an error", tt.input)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: wrong error message. got=%q, want=%q", tt.input, errObj.Message, tt.expected)
+		}
+	}
+}
+
+func TestStringComparison(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{`"apple" < "banana"`, true},
+		{`"apple" > "banana"`, false},
+		{`"b" > "abc"`, true},
+		{`"a" < "a"`, false},
+		{`"a" <= "a"`, true},
+		{`"a" >= "b"`, false},
+		{`"a" == "a"`, true},
+		{`string s = "a"; s + "b" == "ab"`, true},
+		{`"a" != "a"`, false},
+		{`"a" != "b"`, true},
+	}
+
+	for _, tt := range tests {
+		testBooleanObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestFloatExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1.5", "1.5"},
+		{"2.0", "2.0"},
+		{"1.5 + 2.25", "3.75"},
+		{"1 + 0.5", "1.5"},
+		{"7.0 / 2", "3.5"},
+		{"-2.5 * 2", "-5.0"},
+		{"5.5 % 2", "1.5"},
+		{"1.5 < 2", "true"},
+		{"2 == 2.0", "true"},
+		{"float x = 0.1; x = x + 0.2; x > 0.3", "true"},
+		{"fn half(float x) float { return x / 2 } half(3.0)", "1.5"},
+		{"float x = 1; x", "1.0"},
+		{"float? x = 2; x", "2.0"},
+		{"float x = 0.5; x = 3; x", "3.0"},
+		{"fn half(float x) float { return x / 2 } half(3)", "1.5"},
+		{"fn all(float ...xs) { return xs } all(1, 0.5)", "[1.0, 0.5]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil {
+			t.Errorf("%s: got nil", tt.input)
+			continue
+		}
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: wrong result. got=%q, want=%q", tt.input, evaluated.Inspect(), tt.expected)
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"1.5 / 0", "division by zero: 1.5 / 0.0"},
+		{"float x = true", "type mismatch: cannot assign BOOLEAN to `x` of type FLOAT"},
+		{"int x = 1.5", "type mismatch: cannot assign FLOAT to `x` of type INTEGER"},
+		{"fn f(int x) { x } f(1.0)", "argument `x` to `f` must be INTEGER, got FLOAT"},
+	}
+	for _, tt := range errorTests {
+		errObj, ok := testEval(tt.input).(*object.Error)
+		if !ok {
+			t.Errorf("%s: expected an error", tt.input)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: wrong error message. got=%q, want=%q", tt.input, errObj.Message, tt.expected)
+		}
+	}
+}
+
+func TestMathModule(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"math.abs(-3)", "3"},
+		{"math.abs(-2.5)", "2.5"},
+		{"math.min(3, 1, 2)", "1"},
+		{"math.max(3, 1.5)", "3.0"},
+		{"math.pow(2, 10)", "1024"},
+		{"math.pow(2, -1)", "0.5"},
+		{"math.pow(4, 0.5)", "2.0"},
+		{"math.sqrt(16)", "4.0"},
+		{"math.floor(2.7)", "2"},
+		{"math.floor(-2.5)", "-3"},
+		{"math.ceil(2.1)", "3"},
+		{"math.round(2.5)", "3"},
+		{"math.round(7)", "7"},
+		{"math.sin(0)", "0.0"},
+		{"math.cos(0)", "1.0"},
+		{"math.atan2(0, 1)", "0.0"},
+		{"math.log(math.e)", "1.0"},
+		{"math.log10(1000)", "3.0"},
+		{"math.log2(8)", "3.0"},
+		{"math.floor(math.pi * 100)", "314"},
+		{"math.gcd(12, -18)", "6"},
+		{"math.lcm(4, 6)", "12"},
+		{"math.clamp(15, 0, 10)", "10"},
+		{"math.clamp(-1, 0, 10)", "0"},
+		{"math.clamp(0.5, 0, 10)", "0.5"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil {
+			t.Errorf("%s: got nil", tt.input)
+			continue
+		}
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: wrong result. got=%q, want=%q", tt.input, evaluated.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestMathModuleErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"math.sqrt(-1)", "domain error: math.sqrt(-1)"},
+		{"math.log(0)", "domain error: math.log(0)"},
+		{"math.asin(2)", "domain error: math.asin(2)"},
+		{"math.pow(-8, 0.5)", "domain error: math.pow(-8, 0.5)"},
+		{"math.pow(0, -1)", "domain error: math.pow(0, -1)"},
+		{"math.pow(10, 19)", "integer overflow: math.pow(10, 19)"},
+		{"math.lcm(9223372036854775807, 2)", "integer overflow: math.lcm(9223372036854775807, 2)"},
+		{"math.clamp(1, 10, 0)", "`math.clamp` lower bound 10 is greater than upper bound 0"},
+		{`math.abs("a")`, "argument 1 to `math.abs` must be INTEGER or FLOAT, got STRING"},
+		{`math.sin("a")`, "argument 1 to `math.sin` must be FLOAT, got STRING"},
+		{"math.floor(9223372036854775807.0 * 2)", "`math.floor` result 1.8446744073709552e+19 does not fit in an integer"},
+	}
+
+	for _, tt := range tests {
+		errObj, ok := testEval(tt.input).(*object.Error)
+		if !ok {
+			t.Errorf("%s: expected an error", tt.input)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: wrong error message. got=%q, want=%q", tt.input, errObj.Message, tt.expected)
+		}
+	}
+}
+
+func TestSprintf(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`sprintf("plain")`, "plain"},
+		{`sprintf("%d apples", 3)`, "3 apples"},
+		{`sprintf("%5d|%-5d|%05d", 42, 42, 42)`, "   42|42   |00042"},
+		{`sprintf("%f", 1.5)`, "1.500000"},
+		{`sprintf("%.2f", 3.14159)`, "3.14"},
+		{`sprintf("%8.3f", 2)`, "   2.000"},
+		{`sprintf("%s and %s", "salt", "pepper")`, "salt and pepper"},
+		{`sprintf("%-6s|", "ab")`, "ab    |"},
+		{`sprintf("%.3s", "abcdef")`, "abc"},
+		{`sprintf("%v %v %v", 1, true, 2.5)`, "1 true 2.5"},
+		{`sprintf("%q", "hi")`, `"hi"`},
+		{`sprintf("100%%")`, "100%"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		str, ok := evaluated.(*object.String)
+		if !ok {
+			t.Errorf("%s: expected a string. got=%T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if str.Value != tt.expected {
+			t.Errorf("%s: wrong result. got=%q, want=%q", tt.input, str.Value, tt.expected)
+		}
+	}
+}
+
+func TestSprintfErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`sprintf("%d", "a")`, "`sprintf` verb %d needs INTEGER, got STRING"},
+		{`sprintf("%f", true)`, "`sprintf` verb %f needs FLOAT, got BOOLEAN"},
+		{`sprintf("%d and %d", 1)`, "`sprintf` is missing an argument for %d"},
+		{`sprintf("%d", 1, 2)`, "`sprintf` got 2 arguments but the format only uses 1"},
+		{`sprintf("%x", 1)`, "`sprintf` unknown verb %x"},
+		{`sprintf("50%")`, "`sprintf` format ends in the middle of a verb: \"%\""},
+		{`sprintf(1)`, "argument 1 to `sprintf` must be STRING, got INTEGER"},
+		{`printf("%d", "a")`, "`printf` verb %d needs INTEGER, got STRING"},
+	}
+
+	for _, tt := range tests {
+		errObj, ok := testEval(tt.input).(*object.Error)
+		if !ok {
+			t.Errorf("%s: expected an error", tt.input)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: wrong error message. got=%q, want=%q", tt.input, errObj.Message, tt.expected)
+		}
+	}
+}
+
+func TestInterpolatedStrings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`string name = "ada"; int age = 36; "hello ${name}, you are ${age + 1}"`, "hello ada, you are 37"},
+		{`"${1.5} ${true} ${2 < 1}"`, "1.5 true false"},
+		{`string s = "x"; "${s + "}"}"`, "x}"},
+		{`string s = "in"; "out ${"mid ${s}"}"`, "out mid in"},
+		{`fn greet(string n) string { return "hi ${n}" } greet("bob")`, "hi bob"},
+		{`"${sprintf("%03d", 7)}"`, "007"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		str, ok := evaluated.(*object.String)
+		if !ok {
+			t.Errorf("%s: expected a string. got=%T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if str.Value != tt.expected {
+			t.Errorf("%s: wrong result. got=%q, want=%q", tt.input, str.Value, tt.expected)
+		}
+	}
+
+	errObj, ok := testEval(`"a ${missing} b"`).(*object.Error)
+	if !ok || errObj.Message != "identifier not found: missing" {
+		t.Errorf("expected errors in the expressions to come through. got=%+v", errObj)
+	}
+}
+
+func TestNullable(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"null", nil},
+		{"int? a = null; a", nil},
+		{"int? a; a", nil},
+		{"int? a = 5; a", 5},
+		{"int? a = 5; a = null; a", nil},
+		{"int? a = null; a ?? 7", 7},
+		{"int? a = 3; a ?? 7", 3},
+		{"int? a = 3; a ?? missing", 3},
+		{"string? s; s == null", true},
+		{"string? s; s?.len", nil},
+		{"fn f(int? a) int { if a == null { return 0 } return a + 1 } f(null) + f(4)", 5},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestNullableErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"int a = null", "type mismatch: cannot assign NULL to `a` of type INTEGER"},
+		{"int a = 1; a = null", "type mismatch: cannot assign NULL to `a` of type INTEGER"},
+		{"int? a = \"x\"", "type mismatch: cannot assign STRING to `a` of type INTEGER"},
+		{"fn f(int a) { a } f(null)", "argument `a` to `f` must be INTEGER, got NULL"},
+		{"int? a; a + 1", "type mismatch: NULL + INTEGER"},
+		{"int? a; a.b", "member access not supported: NULL.b"},
+	}
+	for _, tt := range tests {
+		errObj, ok := testEval(tt.input).(*object.Error)
+		if !ok {
+			t.Errorf("%s: expected an error. got=%T", tt.input, testEval(tt.input))
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: wrong error message. expected=%q, got=%q", tt.input, tt.expected, errObj.Message)
+		}
+	}
+}
+
+func TestArrayComparison(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"int []a = [1, 2]; int []b = [1, 2]; a == b", true},
+		{"int []a = [1, 2]; int []b = [1, 3]; a != b", true},
+		{"int []a = [1, 2]; float []b = [1.0, 2.0]; a == b", true},
+		{"int []a = [1, 2]; int []b = [1, 2, 3]; a == b", false},
+		{"int []a = [1, 2]; int []b = [1, 3]; a < b", true},
+		{"int []a = [1, 2]; int []b = [1, 2, 0]; a < b", true},
+		{"int []a = [2]; int []b = [1, 2, 0]; a > b", true},
+		{"int []a = [1, 2]; int []b = [1, 2]; a <= b", true},
+		{"int []a = [1, 2]; int []b = [1, 2]; a < b", false},
+		{`string []a = ["b"]; string []b = ["a", "z"]; a >= b`, true},
+		{"int []a = []; int []b = []; a == b", true},
+		{"null == null", true},
+		{"1 == null", false},
+		{`"a" != null`, true},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if !testBooleanObject(t, evaluated, tt.expected) {
+			t.Errorf("wrong result for %s", tt.input)
+		}
+	}
+}
+
+func TestComparisonErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`1 == "1"`, "type mismatch: INTEGER == STRING"},
+		{`true != 1`, "type mismatch: BOOLEAN != INTEGER"},
+		{`"a" < 1`, "type mismatch: STRING < INTEGER"},
+		{`int []a = [1]; a == 1`, "type mismatch: ARRAY == INTEGER"},
+		{`int []a = [1]; string []b = ["1"]; a < b`, "type mismatch: INTEGER < STRING"},
+		{`bool []a = [true]; bool []b = [false]; a < b`, "unknown operator: BOOLEAN < BOOLEAN"},
+		{`true <= false`, "unknown operator: BOOLEAN <= BOOLEAN"},
+		{`int []a = [1]; a - a`, "unknown operator: ARRAY - ARRAY"},
+	}
+
+	for _, tt := range tests {
+		errObj, ok := testEval(tt.input).(*object.Error)
+		if !ok {
+			t.Errorf("%s: expected an error. got=%T", tt.input, testEval(tt.input))
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: wrong error message. expected=%q, got=%q", tt.input, tt.expected, errObj.Message)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package evaluator
+
+import (
+	"fmt"
+	"limLang/object"
+	"strings"
+)
+
+// formatString fills in the verbs of format with args, for printf and
+// sprintf. Verbs look like Go's: %[flags][width][.precision]verb where flags
+// are - (pad on the right) and 0 (pad with zeros) and the verbs are
+//
+//	%d  an integer
+//	%f  a float or integer, 6 decimals unless a precision is given
+//	%s  a string, other values the way print shows them
+//	%v  any value the way print shows it
+//	%q  a quoted string
+//	%%  a percent sign
+func formatString(name, format string, args []object.Object) (string, *object.Error) {
+	var out strings.Builder
+	argIdx := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			out.WriteByte(format[i])
+			continue
+		}
+
+		start := i
+		i++
+		for i < len(format) && (format[i] == '-' || format[i] == '0') {
+			i++
+		}
+		for i < len(format) && isDigit(format[i]) {
+			i++
+		}
+		if i < len(format) && format[i] == '.' {
+			i++
+			for i < len(format) && isDigit(format[i]) {
+				i++
+			}
+		}
+		if i >= len(format) {
+			return "", newError("`%s` format ends in the middle of a verb: %q", name, format[start:])
+		}
+		spec, verb := format[start:i], format[i]
+		if verb == '%' {
+			out.WriteByte('%')
+			continue
+		}
+
+		if argIdx >= len(args) {
+			return "", newError("`%s` is missing an argument for %s%c", name, spec, verb)
+		}
+		arg := orNull(args[argIdx])
+		argIdx++
+
+		var value interface{}
+		switch verb {
+		case 'd':
+			integer, ok := arg.(*object.Integer)
+			if !ok {
+				return "", newError("`%s` verb %s%c needs INTEGER, got %s", name, spec, verb, arg.Type())
+			}
+			value = integer.Value
+		case 'f':
+			if !isNumber(arg) {
+				return "", newError("`%s` verb %s%c needs FLOAT, got %s", name, spec, verb, arg.Type())
+			}
+			value = toFloat(arg).Value
+		case 's', 'v', 'q':
+			value = arg.Inspect()
+			if verb == 'v' {
+				verb = 's'
+			}
+		default:
+			return "", newError("`%s` unknown verb %s%c", name, spec, verb)
+		}
+		out.WriteString(fmt.Sprintf(spec+string(verb), value))
+	}
+
+	if argIdx < len(args) {
+		return "", newError("`%s` got %d arguments but the format only uses %d", name, len(args), argIdx)
+	}
+	return out.String(), nil
+}
+
+func isDigit(ch byte) bool {
+	return '0' <= ch && ch <= '9'
+}
+
+// formatArguments checks the format argument of printf and sprintf and
+// formats the rest with it
+func formatArguments(name string, args []object.Object) (string, *object.Error) {
+	if len(args) < 1 {
+		return "", newError("wrong number of arguments. got=%d, want at least 1", len(args))
+	}
+	format, errObj := stringArgument(name, args, 0)
+	if errObj != nil {
+		return "", errObj
+	}
+	return formatString(name, format, args[1:])
+}
@@ -0,0 +1,129 @@
+package evaluator
+
+import (
+	"fmt"
+	"limLang/object"
+	"reflect"
+	"unicode"
+	"unicode/utf8"
+)
+
+// NewHost wraps a Go struct pointer or map so it can be put in an
+// environment. Scripts can read and write the exported fields (or map
+// entries) and call the exported methods of v.
+func NewHost(v interface{}) (*object.Host, error) {
+	if !isHostValue(reflect.ValueOf(v)) {
+		return nil, fmt.Errorf("host objects must be struct pointers or maps with string keys, got %T", v)
+	}
+	return &object.Host{Value: v}, nil
+}
+
+func isHostValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer:
+		return !v.IsNil() && v.Elem().Kind() == reflect.Struct
+	case reflect.Map:
+		return !v.IsNil() && v.Type().Key().Kind() == reflect.String
+	}
+	return false
+}
+
+// memberNames are the Go names `obj.name` can refer to, scripts may write
+// exported names with a lower case first letter.
+func memberNames(name string) []string {
+	r, size := utf8.DecodeRuneInString(name)
+	if unicode.IsUpper(r) {
+		return []string{name}
+	}
+	return []string{name, string(unicode.ToUpper(r)) + name[size:]}
+}
+
+func evalMemberExpression(obj object.Object, name string) object.Object {
+	if module, ok := obj.(*object.Module); ok {
+		if member, ok := module.Members[name]; ok {
+			return member
+		}
+		return newError("module `%s` has no member `%s`", module.Name, name)
+	}
+	host, ok := obj.(*object.Host)
+	if obj == nil {
+		return newError("member access not supported: null.%s", name)
+	} else if !ok {
+		return newError("member access not supported: %s.%s", obj.Type(), name)
+	}
+	v := reflect.ValueOf(host.Value)
+
+	for _, goName := range memberNames(name) {
+		if method := v.MethodByName(goName); method.IsValid() {
+			builtin, err := NewBuiltin(name, method.Interface())
+			if err != nil {
+				return newError("method `%s` can't be called from lim: %s", name, err)
+			}
+			return builtin
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if field, ok := hostField(v, name); ok {
+			return goValueToObject(field)
+		}
+	case reflect.Map:
+		key := reflect.ValueOf(name).Convert(v.Type().Key())
+		if val := v.MapIndex(key); val.IsValid() {
+			return goValueToObject(val)
+		}
+		return NULL
+	}
+	return newError("%T has no field or method `%s`", host.Value, name)
+}
+
+func evalMemberAssignment(obj object.Object, name string, val object.Object) object.Object {
+	host, ok := obj.(*object.Host)
+	if obj == nil {
+		return newError("member access not supported: null.%s", name)
+	} else if !ok {
+		return newError("member access not supported: %s.%s", obj.Type(), name)
+	}
+	v := reflect.ValueOf(host.Value)
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		field, ok := hostField(v, name)
+		if !ok {
+			return newError("%T has no field `%s`", host.Value, name)
+		}
+		goVal, err := ToGoValue(val, field.Type())
+		if err != nil {
+			return newError("cannot assign %s to field `%s` of %T: %s", val.Type(), name, host.Value, err)
+		}
+		field.Set(goVal)
+	case reflect.Map:
+		goVal, err := ToGoValue(val, v.Type().Elem())
+		if err != nil {
+			return newError("cannot assign %s to `%s` of %T: %s", val.Type(), name, host.Value, err)
+		}
+		v.SetMapIndex(reflect.ValueOf(name).Convert(v.Type().Key()), goVal)
+	}
+	return nil
+}
+
+// hostField finds the exported field called name of the struct v points to,
+// unexported fields are never found.
+func hostField(v reflect.Value, name string) (reflect.Value, bool) {
+	for _, goName := range memberNames(name) {
+		sf, ok := v.Elem().Type().FieldByName(goName)
+		if ok && sf.IsExported() {
+			return v.Elem().FieldByIndex(sf.Index), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func goValueToObject(v reflect.Value) object.Object {
+	obj, err := toObject(v)
+	if err != nil {
+		return newError("%s", err)
+	}
+	return obj
+}
@@ -0,0 +1,159 @@
+package evaluator
+
+import (
+	"errors"
+	"limLang/lexer"
+	"limLang/object"
+	"limLang/parser"
+	"testing"
+)
+
+type testAddress struct {
+	City string
+}
+
+type testUser struct {
+	Name    string
+	Age     int
+	Tags    []string
+	Address testAddress
+	Friend  *testUser
+	secret  string
+}
+
+func (u *testUser) Greet(greeting string) string {
+	return greeting + " " + u.Name
+}
+
+func (u *testUser) Birthday() (int, error) {
+	if u.Age >= 150 {
+		return 0, errors.New("too old")
+	}
+	u.Age++
+	return u.Age, nil
+}
+
+func (u *testUser) reveal() string { return u.secret }
+
+func testEvalHost(input string, hosts map[string]interface{}) object.Object {
+	env := object.NewEnvironment()
+	for name, v := range hosts {
+		host, err := NewHost(v)
+		if err != nil {
+			panic(err)
+		}
+		env.Set(name, host)
+	}
+	l := lexer.New(input)
+	p := parser.New(l)
+	return Eval(p.ParseProgram(), env)
+}
+
+func TestHostObjects(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`user.Name`, "ada"},
+		{`user.name`, "ada"},
+		{`user.Age + 1`, 37},
+		{`len(user.Tags)`, 2},
+		{`user.Address.City`, "london"},
+		{`user.Friend.Name`, "bob"},
+		{`user.Greet("hello")`, "hello ada"},
+		{`user.greet("hi")`, "hi ada"},
+		{`user.Birthday()`, 37},
+		{`user.Greet(1)`, errors.New("argument 1 to `Greet` must be STRING, got INTEGER")},
+		{`user.secret`, errors.New("*evaluator.testUser has no field or method `secret`")},
+		{`user.reveal()`, errors.New("*evaluator.testUser has no field or method `reveal`")},
+		{`user.missing`, errors.New("*evaluator.testUser has no field or method `missing`")},
+		{`int a = 1; a.b`, errors.New("member access not supported: INTEGER.b")},
+		{`settings.theme`, "dark"},
+		{`settings.missing`, nil},
+		{`user.Age = "old"`, errors.New("cannot assign STRING to field `Age` of *evaluator.testUser: cannot use STRING as int")},
+	}
+	for _, tt := range tests {
+		user := &testUser{Name: "ada", Age: 36, Tags: []string{"a", "b"}, Address: testAddress{City: "london"}, Friend: &testUser{Name: "bob"}, secret: "hidden"}
+		hosts := map[string]interface{}{"user": user, "settings": map[string]string{"theme": "dark"}}
+		evaluated := testEvalHost(tt.input, hosts)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testStringObject(t, evaluated, expected)
+		case error:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error for %q. got=%T (%+v)", tt.input, evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected.Error() {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected.Error(), errObj.Message)
+			}
+		case nil:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestHostObjectAssignment(t *testing.T) {
+	user := &testUser{Name: "ada", Address: testAddress{City: "london"}}
+	settings := map[string]int{"volume": 3}
+	input := `
+	user.Name = "grace"
+	user.age = 40
+	user.Address.City = "paris"
+	string []tags = ["x", "y", "z"]
+	user.Tags = tags
+	settings.volume = settings.volume + 1
+	`
+	evaluated := testEvalHost(input, map[string]interface{}{"user": user, "settings": settings})
+	if isError(evaluated) {
+		t.Fatalf("unexpected error: %s", evaluated.Inspect())
+	}
+	if user.Name != "grace" || user.Age != 40 || user.Address.City != "paris" || len(user.Tags) != 3 {
+		t.Errorf("fields were not written. got=%+v", user)
+	}
+	if settings["volume"] != 4 {
+		t.Errorf("map entry was not written. got=%d", settings["volume"])
+	}
+}
+
+func TestNewHostRejectsOtherValues(t *testing.T) {
+	tests := []interface{}{
+		testUser{},
+		(*testUser)(nil),
+		map[int]string{},
+		5,
+	}
+	for _, v := range tests {
+		if _, err := NewHost(v); err == nil {
+			t.Errorf("expected an error for %T", v)
+		}
+	}
+}
+
+func TestHostObjectEquality(t *testing.T) {
+	hosts := map[string]interface{}{
+		"a":     &testUser{Name: "ada", Tags: []string{"x"}},
+		"b":     &testUser{Name: "ada", Tags: []string{"x"}},
+		"c":     &testUser{Name: "bob"},
+		"m":     map[string]int{"one": 1},
+		"other": map[string]int{"one": 1},
+	}
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"a == b", true},
+		{"a != c", true},
+		{"a.Address == c.Address", true},
+		{"m == other", true},
+	}
+	for _, tt := range tests {
+		if !testBooleanObject(t, testEvalHost(tt.input, hosts), tt.expected) {
+			t.Errorf("wrong result for %s", tt.input)
+		}
+	}
+}
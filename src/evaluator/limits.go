@@ -0,0 +1,45 @@
+package evaluator
+
+import (
+	"limLang/object"
+)
+
+// rough sizes of what the evaluator allocates, only used to enforce
+// Limits.MaxMemory so they don't have to be exact
+const (
+	objectHeaderSize = 16
+	pointerSize      = 8
+	environmentSize  = 96
+)
+
+// limitError reports that the run went over one of its limits, the cause
+// is kept so hosts can check which one with errors.Is
+func limitError(err error) *object.Error {
+	return &object.Error{Message: err.Error(), Cause: err}
+}
+
+// track charges a newly created object to the run it was created in
+func track(obj object.Object, env *object.Environment) object.Object {
+	if obj == nil || isError(obj) {
+		return obj
+	}
+	if err := env.Runtime().Allocate(objectSize(obj)); err != nil {
+		return limitError(err)
+	}
+	return obj
+}
+
+func objectSize(obj object.Object) int64 {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return objectHeaderSize
+	case *object.String:
+		return objectHeaderSize + int64(len(obj.Value))
+	case *object.Array:
+		return objectHeaderSize + pointerSize*int64(len(obj.Elements))
+	case *object.Boolean, *object.Null:
+		// shared, nothing new is created for them
+		return 0
+	}
+	return objectHeaderSize
+}
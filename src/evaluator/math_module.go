@@ -0,0 +1,276 @@
+package evaluator
+
+import (
+	"fmt"
+	"limLang/object"
+	"math"
+	"strconv"
+)
+
+// the math module. Functions that take integers or floats keep integers as
+// integers when they can (abs, min, max, pow, clamp), floor, ceil and round
+// give integers and everything else gives floats.
+func init() {
+	modules["math"] = newModule("math", map[string]interface{}{
+		"pi":    &object.Float{Value: math.Pi},
+		"e":     &object.Float{Value: math.E},
+		"abs":   &object.Builtin{Name: "math.abs", Fn: builtinAbs},
+		"min":   &object.Builtin{Name: "math.min", Fn: builtinMin},
+		"max":   &object.Builtin{Name: "math.max", Fn: builtinMax},
+		"clamp": &object.Builtin{Name: "math.clamp", Fn: builtinClamp},
+		"pow":   &object.Builtin{Name: "math.pow", Fn: builtinPow},
+		"floor": &object.Builtin{Name: "math.floor", Fn: roundingBuiltin("math.floor", math.Floor)},
+		"ceil":  &object.Builtin{Name: "math.ceil", Fn: roundingBuiltin("math.ceil", math.Ceil)},
+		"round": &object.Builtin{Name: "math.round", Fn: roundingBuiltin("math.round", math.Round)},
+		"sqrt":  domainChecked("math.sqrt", math.Sqrt, func(x float64) bool { return x >= 0 }),
+		"log":   domainChecked("math.log", math.Log, positive),
+		"log2":  domainChecked("math.log2", math.Log2, positive),
+		"log10": domainChecked("math.log10", math.Log10, positive),
+		"sin":   math.Sin,
+		"cos":   math.Cos,
+		"tan":   math.Tan,
+		"asin":  domainChecked("math.asin", math.Asin, withinOne),
+		"acos":  domainChecked("math.acos", math.Acos, withinOne),
+		"atan":  math.Atan,
+		"atan2": math.Atan2,
+		"gcd":   gcd,
+		"lcm":   lcm,
+	})
+}
+
+func positive(x float64) bool  { return x > 0 }
+func withinOne(x float64) bool { return x >= -1 && x <= 1 }
+
+// domainChecked wraps fn so calling it outside of its domain is a lim error
+// instead of NaN or infinity
+func domainChecked(name string, fn func(float64) float64, inDomain func(float64) bool) func(float64) (float64, error) {
+	return func(x float64) (float64, error) {
+		if !inDomain(x) {
+			return 0, fmt.Errorf("domain error: %s(%s)", name, formatFloat(x))
+		}
+		return fn(x), nil
+	}
+}
+
+func formatFloat(x float64) string {
+	return strconv.FormatFloat(x, 'g', -1, 64)
+}
+
+func builtinAbs(env *object.Environment, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments to `math.abs`. got=%d, want=1", len(args))
+	}
+	switch x := args[0].(type) {
+	case *object.Integer:
+		if x.Value == math.MinInt64 {
+			return newError("integer overflow: math.abs(%d)", x.Value)
+		}
+		if x.Value < 0 {
+			return &object.Integer{Value: -x.Value}
+		}
+		return x
+	case *object.Float:
+		return &object.Float{Value: math.Abs(x.Value)}
+	}
+	return newError("argument 1 to `math.abs` must be INTEGER or FLOAT, got %s", typeOf(args[0]))
+}
+
+func builtinMin(env *object.Environment, args ...object.Object) object.Object {
+	return extreme("math.min", args, "<")
+}
+
+func builtinMax(env *object.Environment, args ...object.Object) object.Object {
+	return extreme("math.max", args, ">")
+}
+
+// extreme picks the argument that is the most operator (< or >) than all
+// the others. If any of them is a float the result is a float.
+func extreme(name string, args []object.Object, operator string) object.Object {
+	if len(args) == 0 {
+		return newError("wrong number of arguments to `%s`. got=0, want at least 1", name)
+	}
+	if errObj := numberArguments(name, args); errObj != nil {
+		return errObj
+	}
+	best := args[0]
+	for _, arg := range args[1:] {
+		if compareNumbers(arg, operator, best) {
+			best = arg
+		}
+	}
+	if anyFloat(args) {
+		return toFloat(best)
+	}
+	return best
+}
+
+// clamp(x, lo, hi) limits x to the range lo to hi
+func builtinClamp(env *object.Environment, args ...object.Object) object.Object {
+	if len(args) != 3 {
+		return newError("wrong number of arguments to `math.clamp`. got=%d, want=3", len(args))
+	}
+	if errObj := numberArguments("math.clamp", args); errObj != nil {
+		return errObj
+	}
+	x, lo, hi := args[0], args[1], args[2]
+	if compareNumbers(lo, ">", hi) {
+		return newError("`math.clamp` lower bound %s is greater than upper bound %s", lo.Inspect(), hi.Inspect())
+	}
+	result := x
+	if compareNumbers(x, "<", lo) {
+		result = lo
+	} else if compareNumbers(x, ">", hi) {
+		result = hi
+	}
+	if anyFloat(args) {
+		return toFloat(result)
+	}
+	return result
+}
+
+// pow gives an integer when both arguments are integers and the exponent
+// isn't negative
+func builtinPow(env *object.Environment, args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments to `math.pow`. got=%d, want=2", len(args))
+	}
+	if errObj := numberArguments("math.pow", args); errObj != nil {
+		return errObj
+	}
+	base, baseIsInt := args[0].(*object.Integer)
+	exp, expIsInt := args[1].(*object.Integer)
+	if baseIsInt && expIsInt && exp.Value >= 0 {
+		result, ok := intPow(base.Value, exp.Value)
+		if !ok {
+			return newError("integer overflow: math.pow(%d, %d)", base.Value, exp.Value)
+		}
+		return &object.Integer{Value: result}
+	}
+
+	x, y := toFloat(args[0]).Value, toFloat(args[1]).Value
+	result := math.Pow(x, y)
+	if math.IsNaN(result) || (x == 0 && y < 0) {
+		return newError("domain error: math.pow(%s, %s)", formatFloat(x), formatFloat(y))
+	}
+	return &object.Float{Value: result}
+}
+
+// intPow is base**exp by squaring, ok is false if it overflows
+func intPow(base, exp int64) (int64, bool) {
+	result := int64(1)
+	for exp > 0 {
+		if exp&1 == 1 {
+			product, ok := mulInt(result, base)
+			if !ok {
+				return 0, false
+			}
+			result = product
+		}
+		exp >>= 1
+		if exp > 0 {
+			square, ok := mulInt(base, base)
+			if !ok {
+				return 0, false
+			}
+			base = square
+		}
+	}
+	return result, true
+}
+
+func mulInt(a, b int64) (int64, bool) {
+	result := a * b
+	if a != 0 && (result/a != b || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64)) {
+		return 0, false
+	}
+	return result, true
+}
+
+// roundingBuiltin makes floor, ceil and round, integers are already round
+// and are given back as they are
+func roundingBuiltin(name string, round func(float64) float64) object.BuiltinFunction {
+	return func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("wrong number of arguments to `%s`. got=%d, want=1", name, len(args))
+		}
+		switch x := args[0].(type) {
+		case *object.Integer:
+			return x
+		case *object.Float:
+			rounded := round(x.Value)
+			// -2**63 is exact as a float64, 2**63 is one past the largest int64
+			if math.IsNaN(rounded) || rounded < math.MinInt64 || rounded >= math.MaxInt64 {
+				return newError("`%s` result %s does not fit in an integer", name, x.Inspect())
+			}
+			return &object.Integer{Value: int64(rounded)}
+		}
+		return newError("argument 1 to `%s` must be INTEGER or FLOAT, got %s", name, typeOf(args[0]))
+	}
+}
+
+func gcd(a, b int64) (int64, error) {
+	if a == math.MinInt64 || b == math.MinInt64 {
+		return 0, fmt.Errorf("integer overflow: math.gcd(%d, %d)", a, b)
+	}
+	if a < 0 {
+		a = -a
+	}
+	if b < 0 {
+		b = -b
+	}
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a, nil
+}
+
+func lcm(a, b int64) (int64, error) {
+	if a == 0 || b == 0 {
+		return 0, nil
+	}
+	divisor, err := gcd(a, b)
+	if err != nil {
+		return 0, fmt.Errorf("integer overflow: math.lcm(%d, %d)", a, b)
+	}
+	if a < 0 {
+		a = -a
+	}
+	if b < 0 {
+		b = -b
+	}
+	result, ok := mulInt(a/divisor, b)
+	if !ok {
+		return 0, fmt.Errorf("integer overflow: math.lcm(%d, %d)", a, b)
+	}
+	return result, nil
+}
+
+func numberArguments(name string, args []object.Object) *object.Error {
+	for i, arg := range args {
+		if arg == nil || !isNumber(arg) {
+			return newError("argument %d to `%s` must be INTEGER or FLOAT, got %s", i+1, name, typeOf(arg))
+		}
+	}
+	return nil
+}
+
+func anyFloat(args []object.Object) bool {
+	for _, arg := range args {
+		if arg.Type() == object.FLOAT_OBJ {
+			return true
+		}
+	}
+	return false
+}
+
+// compareNumbers compares two INTEGER or FLOAT objects, integers are only
+// compared as floats when the other side is a float
+func compareNumbers(left object.Object, operator string, right object.Object) bool {
+	var result object.Object
+	if left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ {
+		result = evalIntegerInfixExpression(operator, left, right, false)
+	} else {
+		result = evalFloatInfixExpression(operator, toFloat(left), toFloat(right))
+	}
+	return result == TRUE
+}
@@ -0,0 +1,28 @@
+package evaluator
+
+import (
+	"limLang/object"
+)
+
+// modules are found like builtins when no variable has their name
+var modules = map[string]*object.Module{}
+
+// newModule builds a module from Go functions, see NewBuiltin, and lim
+// objects which are used as they are. It panics if a function can't be used
+// as a builtin since that's a bug in the module.
+func newModule(name string, members map[string]interface{}) *object.Module {
+	module := &object.Module{Name: name, Members: map[string]object.Object{}}
+	for memberName, member := range members {
+		if obj, ok := member.(object.Object); ok {
+			// builtins written against objects and constants
+			module.Members[memberName] = obj
+			continue
+		}
+		builtin, err := NewBuiltin(name+"."+memberName, member)
+		if err != nil {
+			panic(err)
+		}
+		module.Members[memberName] = builtin
+	}
+	return module
+}
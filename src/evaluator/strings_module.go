@@ -0,0 +1,68 @@
+package evaluator
+
+import (
+	"limLang/object"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// the strings module, lengths and indexes count runes (unicode code points)
+// not bytes like the `len` builtin does
+func init() {
+	modules["strings"] = newModule("strings", map[string]interface{}{
+		"split":       strings.Split,
+		"join":        &object.Builtin{Name: "strings.join", Fn: builtinJoin},
+		"trim":        strings.TrimSpace,
+		"trim_left":   func(s string) string { return strings.TrimLeftFunc(s, unicode.IsSpace) },
+		"trim_right":  func(s string) string { return strings.TrimRightFunc(s, unicode.IsSpace) },
+		"trim_prefix": strings.TrimPrefix,
+		"trim_suffix": strings.TrimSuffix,
+		"to_upper":    strings.ToUpper,
+		"to_lower":    strings.ToLower,
+		"contains":    strings.Contains,
+		"starts_with": strings.HasPrefix,
+		"ends_with":   strings.HasSuffix,
+		"replace":     strings.ReplaceAll,
+		"repeat":      &object.Builtin{Name: "strings.repeat", Fn: builtinRepeat},
+		"index":       runeIndex,
+		"length":      utf8.RuneCountInString,
+	})
+}
+
+// runeIndex is the position of the first sub in s counted in runes, -1 if
+// s doesn't contain it
+func runeIndex(s, sub string) int {
+	i := strings.Index(s, sub)
+	if i < 0 {
+		return -1
+	}
+	return utf8.RuneCountInString(s[:i])
+}
+
+func builtinRepeat(env *object.Environment, args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments to `strings.repeat`. got=%d, want=2", len(args))
+	}
+	s, errObj := stringArgument("strings.repeat", args, 0)
+	if errObj != nil {
+		return errObj
+	}
+	count, ok := args[1].(*object.Integer)
+	if !ok {
+		return newError("argument 2 to `strings.repeat` must be INTEGER, got %s", typeOf(args[1]))
+	}
+	if count.Value < 0 {
+		return newError("argument 2 to `strings.repeat` must not be negative, got %d", count.Value)
+	}
+	// check the size before building the string, a huge one would take
+	// the host down before the memory limit sees it
+	if len(s) > 0 && count.Value > math.MaxInt32/int64(len(s)) {
+		return newError("`strings.repeat` result is too long: %d * %d bytes", count.Value, len(s))
+	}
+	if err := env.Runtime().Allocate(count.Value * int64(len(s))); err != nil {
+		return limitError(err)
+	}
+	return &object.String{Value: strings.Repeat(s, int(count.Value))}
+}
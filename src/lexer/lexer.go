@@ -1,193 +1,330 @@
 package lexer
 
 import (
-	"bytes"
 	"fmt"
+	"io"
 	"limLang/token"
-	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
+// Lexer reads the input as UTF-8, ch is the rune at position and
+// readPosition is the byte offset of the one after it. Token literals are
+// slices of the input, only strings with escapes in them are copied.
 type Lexer struct {
 	input        string
 	position     int
 	readPosition int
-	ch           byte
+	ch           rune
 
 	CurrentLineNumber  int
-	StartOfCurrentLine int
+	StartOfCurrentLine int // byte offset from the start of the source
+
+	// KeepComments makes NextToken attach the comments it skips to the
+	// tokens around them as token.Trivia, for tools that need them like
+	// the doc generator
+	KeepComments bool
+	// comments that will be the leading trivia of the next token, and an
+	// unterminated comment found while looking for trailing ones
+	leading    []token.Token
+	unfinished *token.Token
+	inTrivia   bool // nextTokenWithTrivia is running
+	// runes between the start of the line and position, columns count
+	// runes and not bytes
+	column int
+
+	// one entry for every ${ of a string we are inside of
+	interpolations []interpolation
+
+	// set by NewReader, input then only holds the part of the source
+	// from the current token on and offset is where it starts
+	fromReader bool
+	reader     io.Reader
+	buf        []byte
+	readErr    error
+	offset     int
+}
+
+type interpolation struct {
+	// { } opened in the expression, so we know which } ends it
+	depth int
+	// where the string it is in starts
+	line, column int
 }
 
 func New(input string) *Lexer {
-	l := &Lexer{input: input, CurrentLineNumber: 0, StartOfCurrentLine: 0}
+	l := &Lexer{input: input, CurrentLineNumber: 0, StartOfCurrentLine: 0, column: -1}
+	l.readChar()
+	return l
+}
+
+// readerChunkSize is how much NewReader lexers read at a time
+const readerChunkSize = 64 * 1024
+
+// NewReader lexes the source r gives, it is read a chunk at a time so very
+// large sources don't have to be in memory at once. An error reading r ends
+// the input, Err reports it.
+func NewReader(r io.Reader) *Lexer {
+	l := &Lexer{fromReader: true, reader: r, column: -1}
 	l.readChar()
 	return l
 }
 
+// Err is the error reading the source of a NewReader lexer, nil if there
+// was none or it was read to the end.
+func (l *Lexer) Err() error {
+	return l.readErr
+}
+
+// fill reads from the reader until there are at least n bytes after
+// readPosition or the source ends
+func (l *Lexer) fill(n int) {
+	for l.reader != nil && len(l.input)-l.readPosition < n {
+		if l.buf == nil {
+			l.buf = make([]byte, readerChunkSize)
+		}
+		read, err := l.reader.Read(l.buf)
+		l.input += string(l.buf[:read])
+		if err != nil {
+			if err != io.EOF {
+				l.readErr = err
+			}
+			l.reader = nil
+		}
+	}
+}
+
+// discard drops the input before the current token of a NewReader lexer,
+// tokens already returned keep their literals
+func (l *Lexer) discard() {
+	if !l.fromReader || l.position == 0 {
+		return
+	}
+	shift := min(l.position, len(l.input))
+	l.input = l.input[shift:]
+	l.position -= shift
+	l.readPosition -= shift
+	l.offset += shift
+}
+
 func (l *Lexer) readChar() {
+	if l.ch == '\n' {
+		l.CurrentLineNumber += 1
+		l.StartOfCurrentLine = l.offset + l.readPosition
+		l.column = 0
+	} else {
+		l.column += 1
+	}
+	// enough for this rune, the one peekChar looks at and the third
+	// character of ...
+	if l.reader != nil && len(l.input)-l.readPosition < 2*utf8.UTFMax {
+		l.fill(2 * utf8.UTFMax)
+	}
+	width := 1
 	if l.readPosition >= len(l.input) {
 		l.ch = 0
+	} else if b := l.input[l.readPosition]; b < utf8.RuneSelf {
+		l.ch = rune(b)
 	} else {
-		l.ch = l.input[l.readPosition]
+		// invalid UTF-8 gives utf8.RuneError one byte at a time
+		l.ch, width = utf8.DecodeRuneInString(l.input[l.readPosition:])
 	}
 	l.position = l.readPosition
-	l.readPosition += 1
+	l.readPosition += width
+}
 
-	if l.ch == '\n' {
-		l.CurrentLineNumber += 1
-		l.StartOfCurrentLine = l.readPosition + 1
+// nextTokenWithTrivia is NextToken when KeepComments is set, it gets the
+// token from NextToken and adds the comments around it
+func (l *Lexer) nextTokenWithTrivia() token.Token {
+	if l.unfinished != nil {
+		tok := *l.unfinished
+		l.unfinished = nil
+		return tok
 	}
+	l.inTrivia = true
+	tok := l.NextToken()
+	l.inTrivia = false
+	l.attachTrivia(&tok)
+	return tok
+}
 
+// attachTrivia gives tok the comments before it and the ones after it on
+// the same line. Comments before a newline wait for the next token that
+// isn't one, and so do comments followed by more code on the same line.
+func (l *Lexer) attachTrivia(tok *token.Token) {
+	switch tok.Type {
+	case token.ENDOFLINE, token.ILLEGAL:
+		return
+	case token.EOF:
+		if len(l.leading) > 0 {
+			tok.Trivia = &token.Trivia{Leading: l.leading}
+			l.leading = nil
+		}
+		return
+	}
+	leading := l.leading
+	l.leading = nil
+	if unfinished, ok := l.skipTrivia(); !ok {
+		l.unfinished = &unfinished
+	}
+	var trailing []token.Token
+	if l.ch == '\n' || l.atEOF() {
+		trailing, l.leading = l.leading, nil
+	}
+	if len(leading) > 0 || len(trailing) > 0 {
+		tok.Trivia = &token.Trivia{Leading: leading, Trailing: trailing}
+	}
 }
 
 func (l *Lexer) NextToken() token.Token {
 	var tok token.Token
 
-	l.skipWhitespace()
-	// skips single line comment
-	if l.ch == '/' && l.peekChar() == '/' {
-		for l.ch != '\n' {
-			l.readChar()
-		}
+	l.discard()
+	// kept out of the way of the code below, which runs for every token
+	// and gets a good deal slower when it is called through another
+	// function
+	if l.KeepComments && !l.inTrivia {
+		return l.nextTokenWithTrivia()
 	}
-	// skips multi line comment
-	if l.ch == '/' && l.peekChar() == '*' {
-		for {
-			if l.ch == '*' && l.peekChar() == '/' {
-				break
-			}
-			l.readChar()
-		}
-		l.readChar()
-		l.readChar()
-		fmt.Println(l.ch)
+	if illegal, ok := l.skipTrivia(); !ok {
+		return illegal
 	}
-	l.skipWhitespace()
+	line, column := l.CurrentLineNumber+1, l.column+1
 
 	switch l.ch {
 	case '=':
 		if l.peekChar() == '=' {
-			ch := l.ch
-			l.readChar()
-			tok = token.Token{Type: token.EQ, Literal: string(ch) + string(l.ch)}
+			tok = l.twoCharToken(token.EQ)
 		} else {
-			tok = newToken(token.ASSIGN, l.ch)
+			tok = l.newToken(token.ASSIGN)
 		}
 	case '+':
 		if l.peekChar() == '=' {
-			ch := l.ch
-			l.readChar()
-			tok = token.Token{Type: token.ADD_ASSIGN, Literal: string(ch) + string(l.ch)}
+			tok = l.twoCharToken(token.ADD_ASSIGN)
 		} else {
-			tok = newToken(token.PLUS, l.ch)
+			tok = l.newToken(token.PLUS)
 		}
 	case '-':
 		if l.peekChar() == '>' {
-			ch := l.ch
-			l.readChar()
-			tok = token.Token{Type: token.ARROW, Literal: string(ch) + string(l.ch)}
+			tok = l.twoCharToken(token.ARROW)
 		} else if l.peekChar() == '=' {
-			ch := l.ch
-			l.readChar()
-			tok = token.Token{Type: token.SUB_ASSIGN, Literal: string(ch) + string(l.ch)}
+			tok = l.twoCharToken(token.SUB_ASSIGN)
 		} else {
-			tok = newToken(token.MINUS, l.ch)
+			tok = l.newToken(token.MINUS)
 		}
 	case '*':
 		if l.peekChar() == '=' {
-			ch := l.ch
-			l.readChar()
-			tok = token.Token{Type: token.MUL_ASSIGN, Literal: string(ch) + string(l.ch)}
+			tok = l.twoCharToken(token.MUL_ASSIGN)
 		} else {
-			tok = newToken(token.ASTERISK, l.ch)
+			tok = l.newToken(token.ASTERISK)
 		}
 	case '/':
 		if l.peekChar() == '=' {
-			ch := l.ch
-			l.readChar()
-			tok = token.Token{Type: token.QUO_ASSIGN, Literal: string(ch) + string(l.ch)}
+			tok = l.twoCharToken(token.QUO_ASSIGN)
 		} else {
-			tok = newToken(token.SLASH, l.ch)
+			tok = l.newToken(token.SLASH)
 		}
 	case '%':
 		if l.peekChar() == '=' {
-			ch := l.ch
-			l.readChar()
-			tok = token.Token{Type: token.REM_ASSIGN, Literal: string(ch) + string(l.ch)}
+			tok = l.twoCharToken(token.REM_ASSIGN)
 		} else {
-			tok = newToken(token.MODULUS, l.ch)
+			tok = l.newToken(token.MODULUS)
 		}
 	case '!':
 		if l.peekChar() == '=' {
-			ch := l.ch
-			l.readChar()
-			tok = token.Token{Type: token.NOT_EQ, Literal: string(ch) + string(l.ch)}
+			tok = l.twoCharToken(token.NOT_EQ)
 		} else {
-			tok = newToken(token.BANG, l.ch)
+			tok = l.newToken(token.BANG)
 		}
 	case '<':
 		if l.peekChar() == '=' {
-			ch := l.ch
-			l.readChar()
-			tok = token.Token{Type: token.LTEQ, Literal: string(ch) + string(l.ch)}
+			tok = l.twoCharToken(token.LTEQ)
 		} else {
-			tok = newToken(token.LT, l.ch)
+			tok = l.newToken(token.LT)
 		}
 	case '>':
 		if l.peekChar() == '=' {
-			ch := l.ch
-			l.readChar()
-			tok = token.Token{Type: token.GTEQ, Literal: string(ch) + string(l.ch)}
+			tok = l.twoCharToken(token.GTEQ)
 		} else {
-			tok = newToken(token.GT, l.ch)
+			tok = l.newToken(token.GT)
 		}
 	case ',':
-		tok = newToken(token.COMMA, l.ch)
+		tok = l.newToken(token.COMMA)
 	case ';':
-		tok = newToken(token.SEMICOLON, l.ch)
+		tok = l.newToken(token.SEMICOLON)
 	case ':':
 		if l.peekChar() == '=' {
-			ch := l.ch
-			l.readChar()
-			tok = token.Token{Type: token.DEFINE, Literal: string(ch) + string(l.ch)}
+			tok = l.twoCharToken(token.DEFINE)
 		} else {
-			tok = newToken(token.COLON, l.ch)
+			tok = l.newToken(token.COLON)
+		}
+	case '?':
+		switch l.peekChar() {
+		case '?':
+			tok = l.twoCharToken(token.NULLISH)
+		case '.':
+			tok = l.twoCharToken(token.OPTIONAL_DOT)
+		default:
+			tok = l.newToken(token.QUESTION)
 		}
 	case '|':
 		if l.peekChar() == '|' {
-			ch := l.ch
-			l.readChar()
-			tok = token.Token{Type: token.OR, Literal: string(ch) + string(l.ch)}
+			tok = l.twoCharToken(token.OR)
 		} else {
-			tok = newToken(token.BITWISE_OR, l.ch)
+			tok = l.newToken(token.BITWISE_OR)
 		}
 	case '&':
 		if l.peekChar() == '&' {
-			ch := l.ch
-			l.readChar()
-			tok = token.Token{Type: token.AND, Literal: string(ch) + string(l.ch)}
+			tok = l.twoCharToken(token.AND)
 		} else {
-			tok = newToken(token.BITWISE_AND, l.ch)
+			tok = l.newToken(token.BITWISE_AND)
 		}
 	case '.':
-		tok = newToken(token.PERIOD, l.ch)
+		if l.peekChar() == '.' && l.readPosition+1 < len(l.input) && l.input[l.readPosition+1] == '.' {
+			l.readChar()
+			l.readChar()
+			tok = token.Token{Type: token.ELLIPSIS, Literal: "..."}
+		} else {
+			tok = l.newToken(token.PERIOD)
+		}
 	case '(':
-		tok = newToken(token.LPAREN, l.ch)
+		tok = l.newToken(token.LPAREN)
 	case ')':
-		tok = newToken(token.RPAREN, l.ch)
+		tok = l.newToken(token.RPAREN)
 	case '{':
-		tok = newToken(token.LBRACE, l.ch)
+		if n := len(l.interpolations); n > 0 {
+			l.interpolations[n-1].depth++
+		}
+		tok = l.newToken(token.LBRACE)
 	case '}':
-		tok = newToken(token.RBRACE, l.ch)
+		if n := len(l.interpolations); n > 0 {
+			if in := l.interpolations[n-1]; in.depth == 0 {
+				// end of the ${ expression, back to the string
+				l.interpolations = l.interpolations[:n-1]
+				l.readChar()
+				tok = l.readStringPart(token.TEMPLATE_TAIL, token.TEMPLATE_MIDDLE, in.line, in.column)
+				if tok.Type == token.ILLEGAL {
+					return tok
+				}
+				break
+			}
+			l.interpolations[n-1].depth--
+		}
+		tok = l.newToken(token.RBRACE)
 	case '[':
-		tok = newToken(token.LBRACK, l.ch)
+		tok = l.newToken(token.LBRACK)
 	case ']':
-		tok = newToken(token.RBRACK, l.ch)
+		tok = l.newToken(token.RBRACK)
 	case '\n':
-		tok = newToken(token.ENDOFLINE, l.ch)
+		tok = l.newToken(token.ENDOFLINE)
 	case '"':
 		l.readChar()
-		tok.Literal = l.readString()
-		tok.Type = token.STRING
+		tok = l.readStringPart(token.STRING, token.TEMPLATE_HEAD, line, column)
+	case '`':
+		l.readChar()
+		tok = l.readRawString(line, column)
 	case 0:
 		tok.Literal = ""
 		tok.Type = token.EOF
@@ -195,118 +332,265 @@ func (l *Lexer) NextToken() token.Token {
 		if isLetter(l.ch) {
 			tok.Literal = l.readIdentifier()
 			tok.Type = token.LookupIdent(tok.Literal)
+			tok.Line, tok.Column = line, column
 			return tok
 		} else if isDigit(l.ch) {
-			tok.Type = token.INT
-			tok.Literal = l.realNumber()
+			tok.Literal, tok.Type = l.realNumber()
+			tok.Line, tok.Column = line, column
 			return tok
 		} else {
-			tok = newToken(token.ILLEGAL, l.ch)
-			l.errBreaker("Got illigal token")
+			tok = token.Token{Type: token.ILLEGAL, Literal: fmt.Sprintf("illegal character %q", l.ch)}
 		}
 	}
 
 	l.readChar()
+	tok.Line, tok.Column = line, column
 	return tok
 }
 
-func newToken(tokenType token.TokenType, ch byte) token.Token {
-	return token.Token{Type: tokenType, Literal: string(ch)}
+// newToken is a token for the character we are on
+func (l *Lexer) newToken(tokenType token.TokenType) token.Token {
+	return token.Token{Type: tokenType, Literal: l.input[l.position:l.readPosition]}
+}
+
+// twoCharToken is for operators like == where we are on the first
+// character and the second one is next
+func (l *Lexer) twoCharToken(tokenType token.TokenType) token.Token {
+	start := l.position
+	l.readChar()
+	return token.Token{Type: tokenType, Literal: l.input[start:l.readPosition]}
 }
-func (l *Lexer) realNumber() string {
+
+// realNumber reads an integer like 42, 0xFF, 0o17, 0b1010 or 1_000_000 or
+// a float like 4.2, a float needs digits on both sides of the dot. Whether
+// the digits fit the base is left to the parser. A number running into a
+// letter, like 12abc, is ILLEGAL.
+func (l *Lexer) realNumber() (string, token.TokenType) {
 	position := l.position
-	for isDigit(l.ch) {
+	tokType := token.TokenType(token.INT)
+	if l.ch == '0' && strings.ContainsRune("xXoObB", l.peekChar()) {
+		l.readChar()
 		l.readChar()
+		for isHexDigit(l.ch) || l.ch == '_' {
+			l.readChar()
+		}
+	} else {
+		for isDigit(l.ch) || l.ch == '_' {
+			l.readChar()
+		}
+		if l.ch == '.' && isDigit(l.peekChar()) {
+			tokType = token.FLOAT
+			l.readChar()
+			for isDigit(l.ch) || l.ch == '_' {
+				l.readChar()
+			}
+		}
 	}
 	if isLetter(l.ch) {
-		l.errBreaker("A number shouldn't be as a first character in a identifier name")
+		msg := fmt.Sprintf("invalid character %q in number", l.ch)
+		for isLetter(l.ch) || isDigit(l.ch) {
+			l.readChar()
+		}
+		return msg, token.ILLEGAL
 	}
-	return l.input[position:l.position]
+	return l.input[position:l.position], tokType
 }
 
-func isDigit(ch byte) bool {
+func isDigit(ch rune) bool {
 	return '0' <= ch && ch <= '9'
 }
 
-func (l *Lexer) skipWhitespace() {
-	for l.ch == ' ' || l.ch == '\t' || l.ch == '\r' {
-		l.readChar()
+// skipTrivia skips whitespace and comments up to the next token or newline
+// in one pass, keeping the comments in leading when KeepComments is set. It
+// stops early with an ILLEGAL token, and ok false, for a /* comment that is
+// never closed.
+func (l *Lexer) skipTrivia() (tok token.Token, ok bool) {
+	for {
+		start, line, column := l.position, l.CurrentLineNumber+1, l.column+1
+		switch {
+		case l.ch == ' ' || l.ch == '\t' || l.ch == '\r':
+			l.readChar()
+		case l.ch == '/' && l.peekChar() == '/':
+			for l.ch != '\n' && !l.atEOF() {
+				l.readChar()
+			}
+			if l.KeepComments {
+				l.leading = append(l.leading, token.Token{Type: token.COMMENT, Literal: l.input[start:l.position], Line: line, Column: column})
+			}
+		case l.ch == '/' && l.peekChar() == '*':
+			l.readChar()
+			l.readChar()
+			for l.ch != '*' || l.peekChar() != '/' {
+				if l.atEOF() {
+					return token.Token{Type: token.ILLEGAL, Literal: "unterminated comment", Line: line, Column: column}, false
+				}
+				l.readChar()
+			}
+			l.readChar()
+			l.readChar()
+			if l.KeepComments {
+				l.leading = append(l.leading, token.Token{Type: token.COMMENT, Literal: l.input[start:l.position], Line: line, Column: column})
+			}
+		default:
+			return token.Token{}, true
+		}
 	}
 }
 
+func (l *Lexer) atEOF() bool {
+	return l.position >= len(l.input)
+}
+
 func (l *Lexer) readIdentifier() string {
 	position := l.position
-	for isLetter(l.ch) || isDigit(l.ch) {
+	for isLetter(l.ch) || isDigit(l.ch) || l.ch >= utf8.RuneSelf && unicode.IsDigit(l.ch) {
 		l.readChar()
 	}
 	return l.input[position:l.position]
 }
 
-func isLetter(ch byte) bool {
-	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
+// identifiers start with a Unicode letter or _, after that digits are fine
+// too
+func isLetter(ch rune) bool {
+	if ch < utf8.RuneSelf {
+		return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
+	}
+	return unicode.IsLetter(ch)
 }
 
-func (l *Lexer) readString() string {
-	var str string
-	for l.ch != '"' {
-		str += string(l.ch)
-		l.readChar()
+// readStringPart reads string text up to the closing quote, which gives a
+// token of type end, or up to a ${, which gives one of type interpolated
+// and leaves the lexer inside the expression. line and column are where
+// the string starts, errors point there.
+func (l *Lexer) readStringPart(end, interpolated token.TokenType, line, column int) token.Token {
+	str, interpolates, errMsg := l.readString()
+	if errMsg != "" {
+		return token.Token{Type: token.ILLEGAL, Literal: errMsg, Line: line, Column: column}
 	}
-	return str
-}
-func (l *Lexer) skipInlineNMultiLineComment() {
-	// var str string
-	for l.ch != '*' && l.peekChar() != '/' {
-		// str += string(l.ch)
-		l.readChar()
+	if !interpolates {
+		return token.Token{Type: end, Literal: str}
 	}
+	l.interpolations = append(l.interpolations, interpolation{line: line, column: column})
+	// on the {, NextToken moves past it
 	l.readChar()
-	// return str
+	return token.Token{Type: interpolated, Literal: str}
 }
 
-func (l *Lexer) readSingleLineComment() string {
-	var str string
-	for l.ch != '\n' {
-		str += string(l.ch)
+// the escapes that stand for a single character, \u{...} is handled on its
+// own
+var escapes = map[rune]rune{
+	'n':  '\n',
+	't':  '\t',
+	'r':  '\r',
+	'0':  0,
+	'\\': '\\',
+	'"':  '"',
+	'$':  '$',
+}
+
+// readString stops at the closing quote or at the $ of a ${, interpolates
+// tells which one it was. A string that is never closed or has a bad
+// escape in it gives an error message instead, after a bad escape the rest
+// of the string is skipped so lexing goes on after it. Strings without
+// escapes are slices of the input, the others are built in out.
+func (l *Lexer) readString() (str string, interpolates bool, errMsg string) {
+	var out strings.Builder
+	escaped := false
+	// start of the text since the last escape, it is copied as it is so
+	// invalid UTF-8 isn't replaced
+	start := l.position
+	text := func() string {
+		if !escaped {
+			return l.input[start:l.position]
+		}
+		out.WriteString(l.input[start:l.position])
+		return out.String()
+	}
+	for {
+		if l.position >= len(l.input) {
+			return "", false, "unterminated string"
+		}
+		switch l.ch {
+		case '"':
+			return text(), false, errMsg
+		case '$':
+			if l.peekChar() == '{' && errMsg == "" {
+				return text(), true, ""
+			}
+		case '\\':
+			out.WriteString(l.input[start:l.position])
+			escaped = true
+			l.readChar()
+			if l.ch == 'u' {
+				r, ok := l.readUnicodeEscape()
+				if !ok {
+					if errMsg == "" {
+						errMsg = "invalid unicode escape, want \\u{...} with 1 to 6 hex digits"
+					}
+					// we are on the character that broke the escape,
+					// which might be the closing quote
+					start = l.position
+					continue
+				}
+				out.WriteRune(r)
+			} else if esc, ok := escapes[l.ch]; ok {
+				out.WriteRune(esc)
+			} else if l.position >= len(l.input) {
+				continue
+			} else if errMsg == "" {
+				errMsg = fmt.Sprintf("unknown escape sequence \\%c", l.ch)
+			}
+			start = l.readPosition
+		}
 		l.readChar()
 	}
-	return str
 }
 
-func (l *Lexer) peekChar() byte {
-	if l.readPosition >= len(l.input) {
-		return 0
-	} else {
-		return l.input[l.readPosition]
+// readUnicodeEscape reads the {1F600} of \u{1F600}, it stops on the }
+func (l *Lexer) readUnicodeEscape() (rune, bool) {
+	if l.peekChar() != '{' {
+		return 0, false
 	}
+	l.readChar()
+	l.readChar()
+	start := l.position
+	for isHexDigit(l.ch) {
+		l.readChar()
+	}
+	digits := l.input[start:l.position]
+	if l.ch != '}' || len(digits) == 0 || len(digits) > 6 {
+		return 0, false
+	}
+	r, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil || !utf8.ValidRune(rune(r)) {
+		return 0, false
+	}
+	return rune(r), true
+}
+
+func isHexDigit(ch rune) bool {
+	return isDigit(ch) || 'a' <= ch && ch <= 'f' || 'A' <= ch && ch <= 'F'
 }
 
-func (l *Lexer) errBreaker(errMsg string) {
-	fmt.Println("Error occured at line number :", l.CurrentLineNumber)
-	fmt.Println(errMsg)
-	var errLine bytes.Buffer
-	start := l.StartOfCurrentLine - 1
-	currChar := l.input[start]
-	for currChar == '\t' || currChar == ' ' {
-		start += 1
-		currChar = l.input[start]
+// readRawString reads a `raw string`, it has no escapes or interpolation
+// and can span lines
+func (l *Lexer) readRawString(line, column int) token.Token {
+	start := l.position
+	for l.ch != '`' {
+		if l.position >= len(l.input) {
+			return token.Token{Type: token.ILLEGAL, Literal: "unterminated raw string", Line: line, Column: column}
+		}
+		l.readChar()
 	}
-	nStart := start
-	for currChar != '\n' {
-		errLine.WriteString(string(currChar))
-		start += 1
-		currChar = l.input[start]
+	return token.Token{Type: token.STRING, Literal: l.input[start:l.position]}
+}
+func (l *Lexer) peekChar() rune {
+	if l.readPosition >= len(l.input) {
+		return 0
 	}
-	fmt.Println(errLine.String())
-
-	for nStart < l.readPosition-1 {
-		fmt.Print("-")
-		nStart += 1
+	if b := l.input[l.readPosition]; b < utf8.RuneSelf {
+		return rune(b)
 	}
-	fmt.Print("^")
-
-	// right now we are just breaking the program here but later
-	// we should return a illigal token to the parser
-	// and parser will end program gracefully instead of doing it abruptly like we are doing it rn
-	os.Exit(1)
+	r, _ := utf8.DecodeRuneInString(l.input[l.readPosition:])
+	return r
 }
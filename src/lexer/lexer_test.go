@@ -1,8 +1,13 @@
 package lexer
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"limLang/token"
+	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 func TestNextToken(t *testing.T) {
@@ -74,6 +79,14 @@ func TestVariableInitilizationTokens(t *testing.T) {
 		{token.SEMICOLON, ";"},
 		{token.ENDOFLINE, "\n"},
 
+		// int da42 = 2;
+		{token.Keyword_INT, "int"},
+		{token.IDENT, "da42"},
+		{token.ASSIGN, "="},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.ENDOFLINE, "\n"},
+
 		// bool data = false;
 		{token.Keyword_BOOL, "bool"},
 		{token.IDENT, "data"},
@@ -196,7 +209,7 @@ func nextTokenFn(t *testing.T) {
 }
 
 func nextTokenOperators(t *testing.T) {
-	input := `!-/*7
+	input := `!-/ *7
    	5 < 10 > 5
 	10 <= 10
 	10 >= 10
@@ -390,3 +403,458 @@ func TestComments(t *testing.T) {
 	}
 
 }
+
+func TestTokenPositions(t *testing.T) {
+	input := `int a = 5
+	fn add(int x) {
+		return x + a
+	}`
+	tests := []struct {
+		expectedLiteral string
+		expectedLine    int
+		expectedColumn  int
+	}{
+		{"int", 1, 1},
+		{"a", 1, 5},
+		{"=", 1, 7},
+		{"5", 1, 9},
+		{"\n", 1, 10},
+		{"fn", 2, 2},
+		{"add", 2, 5},
+		{"(", 2, 8},
+		{"int", 2, 9},
+		{"x", 2, 13},
+		{")", 2, 14},
+		{"{", 2, 16},
+		{"\n", 2, 17},
+		{"return", 3, 3},
+		{"x", 3, 10},
+		{"+", 3, 12},
+		{"a", 3, 14},
+		{"\n", 3, 15},
+		{"}", 4, 2},
+	}
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Line != tt.expectedLine || tok.Column != tt.expectedColumn {
+			t.Fatalf("tests[%d] - position wrong. expected=%d:%d, got=%d:%d",
+				i, tt.expectedLine, tt.expectedColumn, tok.Line, tok.Column)
+		}
+	}
+}
+
+func TestInterpolatedStrings(t *testing.T) {
+	input := `"hi ${name}, ${ f("}") + "${x}" } done" {}`
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.TEMPLATE_HEAD, "hi "},
+		{token.IDENT, "name"},
+		{token.TEMPLATE_MIDDLE, ", "},
+		{token.IDENT, "f"},
+		{token.LPAREN, "("},
+		{token.STRING, "}"},
+		{token.RPAREN, ")"},
+		{token.PLUS, "+"},
+		{token.TEMPLATE_HEAD, ""},
+		{token.IDENT, "x"},
+		{token.TEMPLATE_TAIL, ""},
+		{token.TEMPLATE_TAIL, " done"},
+		{token.LBRACE, "{"},
+		{token.RBRACE, "}"},
+		{token.EOF, ""},
+	}
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong token. expected=%s %q, got=%s %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestStringEscapes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"a\nb"`, "a\nb"},
+		{`"tab\there"`, "tab\there"},
+		{`"say \"hi\""`, `say "hi"`},
+		{`"back\\slash"`, `back\slash`},
+		{`"\r\0"`, "\r\x00"},
+		{`"cost \${x}"`, "cost ${x}"},
+		{`"\u{48}\u{e9}\u{1F600}"`, "Hé😀"},
+		{`"héllo"`, "héllo"},
+		{"`raw \\n ${x} \"q\"`", `raw \n ${x} "q"`},
+		{"`two\nlines`", "two\nlines"},
+	}
+
+	for _, tt := range tests {
+		tok := New(tt.input).NextToken()
+		if tok.Type != token.STRING {
+			t.Errorf("%s: expected a STRING token. got=%s %q", tt.input, tok.Type, tok.Literal)
+			continue
+		}
+		if tok.Literal != tt.expected {
+			t.Errorf("%s: wrong literal. got=%q, want=%q", tt.input, tok.Literal, tt.expected)
+		}
+	}
+}
+
+func TestStringErrors(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedMsg    string
+		expectedLine   int
+		expectedColumn int
+	}{
+		{`x = "never closed`, "unterminated string", 1, 5},
+		{"a\n  `raw\nstring", "unterminated raw string", 2, 3},
+		{`"ends with \`, "unterminated string", 1, 1},
+		{`"a ${b} c`, "unterminated string", 1, 1},
+		{`"bad \q escape"`, `unknown escape sequence \q`, 1, 1},
+		{`"bad \u{110000}"`, `invalid unicode escape, want \u{...} with 1 to 6 hex digits`, 1, 1},
+		{`"bad \u{}"`, `invalid unicode escape, want \u{...} with 1 to 6 hex digits`, 1, 1},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		var tok token.Token
+		for tok = l.NextToken(); tok.Type != token.ILLEGAL && tok.Type != token.EOF; tok = l.NextToken() {
+		}
+		if tok.Type != token.ILLEGAL {
+			t.Errorf("%q: expected an ILLEGAL token", tt.input)
+			continue
+		}
+		if tok.Literal != tt.expectedMsg {
+			t.Errorf("%q: wrong message. got=%q, want=%q", tt.input, tok.Literal, tt.expectedMsg)
+		}
+		if tok.Line != tt.expectedLine || tok.Column != tt.expectedColumn {
+			t.Errorf("%q: error should point at the opening quote. expected=%d:%d, got=%d:%d",
+				tt.input, tt.expectedLine, tt.expectedColumn, tok.Line, tok.Column)
+		}
+	}
+
+	// lexing goes on after the bad string
+	l := New(`"bad \q" + 1`)
+	for _, want := range []token.TokenType{token.ILLEGAL, token.PLUS, token.INT, token.EOF} {
+		if tok := l.NextToken(); tok.Type != want {
+			t.Fatalf("expected %s. got=%s %q", want, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestNumberLiterals(t *testing.T) {
+	tests := []struct {
+		input        string
+		expectedType token.TokenType
+	}{
+		{"42", token.INT},
+		{"0xFF", token.INT},
+		{"0o17", token.INT},
+		{"0b1010", token.INT},
+		{"1_000_000", token.INT},
+		{"0x_dead_BEEF", token.INT},
+		{"1_000.000_5", token.FLOAT},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.input {
+			t.Errorf("%q: wrong token. got=%s %q", tt.input, tok.Type, tok.Literal)
+		}
+		if tok := l.NextToken(); tok.Type != token.EOF {
+			t.Errorf("%q: expected EOF after the number. got=%s %q", tt.input, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestUnicode(t *testing.T) {
+	input := `string grüße = "héllo, 世界 😀" // ünïcödé
+	/* 世界 */ int 変数 = 1 + λ2`
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedLine    int
+		expectedColumn  int
+	}{
+		{token.Keyword_STRING, "string", 1, 1},
+		{token.IDENT, "grüße", 1, 8},
+		{token.ASSIGN, "=", 1, 14},
+		{token.STRING, "héllo, 世界 😀", 1, 16},
+		{token.ENDOFLINE, "\n", 1, 40},
+		{token.Keyword_INT, "int", 2, 11},
+		{token.IDENT, "変数", 2, 15},
+		{token.ASSIGN, "=", 2, 18},
+		{token.INT, "1", 2, 20},
+		{token.PLUS, "+", 2, 22},
+		{token.IDENT, "λ2", 2, 24},
+		{token.EOF, "", 2, 26},
+	}
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong token. expected=%s %q, got=%s %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+		if tok.Line != tt.expectedLine || tok.Column != tt.expectedColumn {
+			t.Fatalf("tests[%d] - position wrong. expected=%d:%d, got=%d:%d",
+				i, tt.expectedLine, tt.expectedColumn, tok.Line, tok.Column)
+		}
+	}
+}
+
+func TestIllegalCharacters(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedMsg    string
+		expectedColumn int
+	}{
+		{"a = 5 € 3", "illegal character '€'", 7},
+		{"x = \"ü\" # 1", "illegal character '#'", 9},
+		{"int 12abc = 1", "invalid character 'a' in number", 5},
+		{"\xff", "illegal character '\ufffd'", 1},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		var tok token.Token
+		for tok = l.NextToken(); tok.Type != token.ILLEGAL && tok.Type != token.EOF; tok = l.NextToken() {
+		}
+		if tok.Type != token.ILLEGAL || tok.Literal != tt.expectedMsg {
+			t.Errorf("%q: expected ILLEGAL %q. got=%s %q", tt.input, tt.expectedMsg, tok.Type, tok.Literal)
+		}
+		if tok.Line != 1 || tok.Column != tt.expectedColumn {
+			t.Errorf("%q: wrong position. expected=1:%d, got=%d:%d", tt.input, tt.expectedColumn, tok.Line, tok.Column)
+		}
+	}
+
+	// invalid UTF-8 in strings is kept as it is
+	l := New("\"a\xffb\"")
+	if tok := l.NextToken(); tok.Type != token.STRING || tok.Literal != "a\xffb" {
+		t.Errorf("wrong token for invalid UTF-8 string. got=%s %q", tok.Type, tok.Literal)
+	}
+}
+
+func TestCommentErrors(t *testing.T) {
+	// comments running into the end of the input
+	for _, input := range []string{"int a // no newline", "int a /* closed */"} {
+		l := New(input)
+		for _, want := range []token.TokenType{token.Keyword_INT, token.IDENT, token.EOF} {
+			if tok := l.NextToken(); tok.Type != want {
+				t.Fatalf("%q: expected %s. got=%s %q", input, want, tok.Type, tok.Literal)
+			}
+		}
+	}
+
+	l := New("int a\n  /* never closed *")
+	var tok token.Token
+	for tok = l.NextToken(); tok.Type != token.ILLEGAL && tok.Type != token.EOF; tok = l.NextToken() {
+	}
+	if tok.Type != token.ILLEGAL || tok.Literal != "unterminated comment" || tok.Line != 2 || tok.Column != 3 {
+		t.Errorf("expected an unterminated comment at 2:3. got=%s %q at %d:%d", tok.Type, tok.Literal, tok.Line, tok.Column)
+	}
+}
+
+func TestKeepComments(t *testing.T) {
+	input := `// adds
+int a /* one */ = 1 // two /* three */
+/* multi
+line */`
+	tests := []struct {
+		expectedType     token.TokenType
+		expectedLiteral  string
+		expectedLine     int
+		expectedColumn   int
+		expectedLeading  []string
+		expectedTrailing []string
+	}{
+		{token.ENDOFLINE, "\n", 1, 8, nil, nil},
+		{token.Keyword_INT, "int", 2, 1, []string{"// adds"}, nil},
+		{token.IDENT, "a", 2, 5, nil, nil},
+		{token.ASSIGN, "=", 2, 17, []string{"/* one */"}, nil},
+		{token.INT, "1", 2, 19, nil, []string{"// two /* three */"}},
+		{token.ENDOFLINE, "\n", 2, 39, nil, nil},
+		{token.EOF, "", 4, 8, []string{"/* multi\nline */"}, nil},
+	}
+	l := New(input)
+	l.KeepComments = true
+
+	literals := func(comments []token.Token) []string {
+		var out []string
+		for _, comment := range comments {
+			if comment.Type != token.COMMENT {
+				t.Errorf("trivia should be COMMENT tokens. got=%s", comment.Type)
+			}
+			out = append(out, comment.Literal)
+		}
+		return out
+	}
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong token. expected=%s %q, got=%s %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+		if tok.Line != tt.expectedLine || tok.Column != tt.expectedColumn {
+			t.Fatalf("tests[%d] - position wrong. expected=%d:%d, got=%d:%d",
+				i, tt.expectedLine, tt.expectedColumn, tok.Line, tok.Column)
+		}
+		var leading, trailing []string
+		if tok.Trivia != nil {
+			leading, trailing = literals(tok.Trivia.Leading), literals(tok.Trivia.Trailing)
+		}
+		if fmt.Sprint(leading) != fmt.Sprint(tt.expectedLeading) || fmt.Sprint(trailing) != fmt.Sprint(tt.expectedTrailing) {
+			t.Fatalf("tests[%d] - wrong trivia. expected=%q %q, got=%q %q",
+				i, tt.expectedLeading, tt.expectedTrailing, leading, trailing)
+		}
+	}
+
+	// the comment positions are kept too
+	l = New("a\n  /* c */ b")
+	l.KeepComments = true
+	l.NextToken()
+	l.NextToken()
+	if tok := l.NextToken(); tok.Trivia == nil || tok.Trivia.Leading[0].Line != 2 || tok.Trivia.Leading[0].Column != 3 {
+		t.Errorf("wrong comment position. got=%+v", tok.Trivia)
+	}
+
+	// an unterminated comment after a token still gives the error
+	l = New("a /* open")
+	l.KeepComments = true
+	if tok := l.NextToken(); tok.Type != token.IDENT {
+		t.Fatalf("expected the identifier first. got=%s %q", tok.Type, tok.Literal)
+	}
+	if tok := l.NextToken(); tok.Type != token.ILLEGAL || tok.Literal != "unterminated comment" || tok.Column != 3 {
+		t.Errorf("expected the unterminated comment. got=%s %q at %d", tok.Type, tok.Literal, tok.Column)
+	}
+}
+
+func TestReader(t *testing.T) {
+	inputs := []string{
+		benchmarkSource(50),
+		"string s = \"héllo ${name} \\u{1F600}\"\n/* 世界 */ int 変数 = 0xFF...",
+		"a /* unterminated",
+	}
+	for _, input := range inputs {
+		// one byte at a time so tokens and runes are split between reads
+		for _, r := range []io.Reader{strings.NewReader(input), iotest.OneByteReader(strings.NewReader(input))} {
+			want := New(input)
+			got := NewReader(r)
+			for {
+				wantTok, gotTok := want.NextToken(), got.NextToken()
+				if gotTok != wantTok {
+					t.Fatalf("wrong token from reader. expected=%+v, got=%+v", wantTok, gotTok)
+				}
+				if wantTok.Type == token.EOF || wantTok.Type == token.ILLEGAL {
+					break
+				}
+			}
+			if got.Err() != nil {
+				t.Errorf("unexpected error: %s", got.Err())
+			}
+		}
+	}
+
+	errRead := errors.New("read failed")
+	l := NewReader(io.MultiReader(strings.NewReader("int a"), iotest.ErrReader(errRead)))
+	for _, want := range []token.TokenType{token.Keyword_INT, token.IDENT, token.EOF} {
+		if tok := l.NextToken(); tok.Type != want {
+			t.Fatalf("expected %s. got=%s %q", want, tok.Type, tok.Literal)
+		}
+	}
+	if l.Err() != errRead {
+		t.Errorf("expected the read error. got=%v", l.Err())
+	}
+}
+
+// benchmarkSource is a made up program of about n lines, like the generated
+// files the lexer has to keep up with
+func benchmarkSource(n int) string {
+	chunk := `// sums up the scores
+fn total(int []scores) int {
+	int sum = 0
+	for (int i = 0; i < len(scores); i += 1) {
+		sum += scores[i] * 2 - 1
+	}
+	return sum
+}
+string name = "player one"
+float ratio = 0.75
+int mask = 0xFF & 0b1010
+string greeting = "hello, ${name}!"
+bool ok = total([1, 2, 3]) >= 10 && ratio != 1.5
+`
+	return strings.Repeat(chunk, n/strings.Count(chunk, "\n")+1)
+}
+
+func benchmarkLexer(b *testing.B, src string, lex func() *Lexer) {
+	b.SetBytes(int64(len(src)))
+	b.ReportAllocs()
+	tokens := 0
+	for i := 0; i < b.N; i++ {
+		l := lex()
+		for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+			tokens++
+		}
+	}
+	b.ReportMetric(float64(tokens)/b.Elapsed().Seconds(), "tokens/s")
+}
+
+// BenchmarkLexer reports tokens/s for about 10000 lines. To compare with
+// an older lexer, copy benchmarkSource, benchmarkLexer and BenchmarkLexer
+// into its lexer_test.go and run both with
+//
+//	go test ./lexer -run XXX -bench 'Lexer$' -count 5
+//
+// one after the other on the same machine, the numbers only mean
+// something next to each other.
+func BenchmarkLexer(b *testing.B) {
+	src := benchmarkSource(10000)
+	benchmarkLexer(b, src, func() *Lexer { return New(src) })
+}
+
+func BenchmarkLexerReader(b *testing.B) {
+	src := benchmarkSource(10000)
+	benchmarkLexer(b, src, func() *Lexer { return NewReader(strings.NewReader(src)) })
+}
+
+func TestNullOperators(t *testing.T) {
+	input := `int? x = a ?? b?.c`
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.Keyword_INT, "int"},
+		{token.QUESTION, "?"},
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.IDENT, "a"},
+		{token.NULLISH, "??"},
+		{token.IDENT, "b"},
+		{token.OPTIONAL_DOT, "?."},
+		{token.IDENT, "c"},
+		{token.EOF, ""},
+	}
+	l := New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong token. expected=%s %q, got=%s %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
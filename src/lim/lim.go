@@ -0,0 +1,257 @@
+// Package lim lets Go programs embed the lim interpreter.
+//
+//	interp := lim.New()
+//	interp.SetStdout(&buf)
+//	result, err := interp.RunString(`int a = 2 * 4; a`)
+package lim
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"limLang/evaluator"
+	"limLang/lexer"
+	"limLang/object"
+	"limLang/optimizer"
+	"limLang/parser"
+	"os"
+	"strings"
+)
+
+// Interpreter runs lim programs. Globals defined by one run stay around for
+// the next one, so a script can be loaded once and its functions called
+// later. Interpreters don't share any state with each other.
+//
+// New interpreters have no capabilities, builtins that touch files, the
+// environment, other processes, the clock or randomness fail until they are
+// granted with SetCapabilities.
+type Interpreter struct {
+	env *object.Environment
+}
+
+// DefaultMaxDepth is the call depth new interpreters stop at, deeper
+// recursion would crash the host by overflowing the Go stack.
+const DefaultMaxDepth = 10000
+
+func New() *Interpreter {
+	env := object.NewEnvironment()
+	env.Runtime().Limits.MaxDepth = DefaultMaxDepth
+	return &Interpreter{env: env}
+}
+
+// Limits bound the steps, call depth and memory of every run, zero means
+// unlimited (except for MaxDepth, see SetLimits).
+type Limits = object.Limits
+
+// A run that goes over its limits or whose context is done fails with a
+// RuntimeError wrapping one of these, check for them with errors.Is.
+var (
+	ErrStepLimit   = object.ErrStepLimit
+	ErrTimeout     = object.ErrTimeout
+	ErrDepthLimit  = object.ErrDepthLimit
+	ErrMemoryLimit = object.ErrMemoryLimit
+)
+
+// Capabilities is what scripts are allowed to do, see SetCapabilities.
+type Capabilities = object.Capabilities
+
+// Capability is one of the things in Capabilities, functions added with
+// RegisterFunc can require them like the builtins do.
+type Capability = object.Capability
+
+const (
+	CapFSRead  = object.CapFSRead
+	CapFSWrite = object.CapFSWrite
+	CapExec    = object.CapExec
+	CapEnv     = object.CapEnv
+	CapClock   = object.CapClock
+	CapRandom  = object.CapRandom
+)
+
+// ErrPermission is wrapped by the RuntimeError of a builtin called without
+// the capability it needs.
+var ErrPermission = object.ErrPermission
+
+// AllCapabilities grants everything, only use it for trusted scripts.
+func AllCapabilities() Capabilities {
+	return object.AllCapabilities()
+}
+
+// ParseError is returned when a program can't be parsed or fails the
+// checks done before it runs.
+type ParseError struct {
+	Errors []string
+}
+
+func (e *ParseError) Error() string {
+	return strings.Join(e.Errors, "\n")
+}
+
+// RuntimeError is returned when a program fails while it runs.
+type RuntimeError struct {
+	Err *object.Error
+}
+
+func (e *RuntimeError) Error() string {
+	return e.Err.Traceback()
+}
+
+func (e *RuntimeError) Unwrap() error {
+	return e.Err
+}
+
+func (i *Interpreter) SetStdout(w io.Writer) {
+	i.env.Runtime().Stdout = w
+}
+
+func (i *Interpreter) SetStdin(r io.Reader) {
+	i.env.Runtime().Stdin = r
+}
+
+// SetCheckedArithmetic makes integer overflow a runtime error.
+func (i *Interpreter) SetCheckedArithmetic(checked bool) {
+	i.env.Runtime().CheckedArithmetic = checked
+}
+
+// SetLimits replaces the limits of the interpreter, they apply to each
+// RunString, RunFile and Call separately. A MaxDepth of zero keeps
+// DefaultMaxDepth, unbounded recursion would crash the host; pass a negative
+// MaxDepth to really turn the depth limit off.
+func (i *Interpreter) SetLimits(limits Limits) {
+	if limits.MaxDepth == 0 {
+		limits.MaxDepth = DefaultMaxDepth
+	}
+	i.env.Runtime().Limits = limits
+}
+
+// SetCapabilities replaces what scripts are allowed to do, for example
+//
+//	interp.SetCapabilities(lim.Capabilities{FSRead: []string{"./data"}, Clock: true})
+func (i *Interpreter) SetCapabilities(caps Capabilities) {
+	i.env.Runtime().Capabilities = caps
+}
+
+// RunString runs src and returns the value of its last statement.
+func (i *Interpreter) RunString(src string) (object.Object, error) {
+	return i.RunStringContext(context.Background(), src)
+}
+
+// RunStringContext is RunString but stops with ErrTimeout once ctx is done.
+func (i *Interpreter) RunStringContext(ctx context.Context, src string) (object.Object, error) {
+	l := lexer.New(src)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, &ParseError{Errors: p.Errors()}
+	}
+
+	o := optimizer.New()
+	program = o.Optimize(program)
+	if len(o.Errors()) != 0 {
+		return nil, &ParseError{Errors: o.Errors()}
+	}
+
+	return i.run(ctx, func() object.Object {
+		return evaluator.Eval(program, i.env)
+	})
+}
+
+func (i *Interpreter) RunFile(path string) (object.Object, error) {
+	return i.RunFileContext(context.Background(), path)
+}
+
+func (i *Interpreter) RunFileContext(ctx context.Context, path string) (object.Object, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return i.RunStringContext(ctx, string(data))
+}
+
+func (i *Interpreter) SetGlobal(name string, val object.Object) {
+	i.env.Set(name, val)
+}
+
+// SetGlobalValue converts the Go value v to a lim object and stores it as
+// name. Struct pointers and maps become host objects scripts can use with
+// `name.field` and `name.Method(x)`.
+func (i *Interpreter) SetGlobalValue(name string, v interface{}) error {
+	obj, err := evaluator.ToObject(v)
+	if err != nil {
+		return err
+	}
+	i.env.Set(name, obj)
+	return nil
+}
+
+func (i *Interpreter) GetGlobal(name string) (object.Object, bool) {
+	return i.env.Get(name)
+}
+
+// RegisterFunc makes the Go function fn callable from scripts as name, see
+// evaluator.NewBuiltin for the functions it accepts. For example
+//
+//	interp.RegisterFunc("greet", func(name string, times int64) (string, error) {
+//		...
+//	})
+//
+// A function that reaches outside the interpreter should list the
+// capabilities it needs, calls fail with ErrPermission unless all of them
+// were granted with SetCapabilities:
+//
+//	interp.RegisterFunc("load", loadConfig, lim.CapFSRead)
+//
+// Only the capability itself is checked, fn has to keep to the FSRead and
+// FSWrite directories on its own.
+func (i *Interpreter) RegisterFunc(name string, fn interface{}, requires ...Capability) error {
+	builtin, err := evaluator.NewBuiltin(name, fn)
+	if err != nil {
+		return err
+	}
+	builtin.Requires = requires
+	i.env.Set(name, builtin)
+	return nil
+}
+
+// Call calls the global function fnName with args.
+func (i *Interpreter) Call(fnName string, args ...object.Object) (object.Object, error) {
+	return i.CallContext(context.Background(), fnName, args...)
+}
+
+func (i *Interpreter) CallContext(ctx context.Context, fnName string, args ...object.Object) (object.Object, error) {
+	fn, ok := i.env.Get(fnName)
+	if !ok {
+		return nil, fmt.Errorf("function not found: %s", fnName)
+	}
+	if fn.Type() != object.FUNCTION_OBJ && fn.Type() != object.BUILTIN_OBJ {
+		return nil, fmt.Errorf("not a function: %s is %s", fnName, fn.Type())
+	}
+	return i.run(ctx, func() object.Object {
+		return evaluator.CallFunction(fn, i.env, args...)
+	})
+}
+
+// run starts a new run with fresh limits for eval
+func (i *Interpreter) run(ctx context.Context, eval func() object.Object) (object.Object, error) {
+	rt := i.env.Runtime()
+	prevCtx := rt.Context
+	rt.Context = ctx
+	rt.Begin()
+	defer func() {
+		rt.End()
+		rt.Context = prevCtx
+	}()
+	return result(eval())
+}
+
+// result turns what the evaluator returned into what we hand to the host,
+// statements evaluate to nil which we report as null.
+func result(obj object.Object) (object.Object, error) {
+	if errObj, ok := obj.(*object.Error); ok {
+		return nil, &RuntimeError{Err: errObj}
+	}
+	if obj == nil {
+		return evaluator.NULL, nil
+	}
+	return obj, nil
+}
@@ -0,0 +1,592 @@
+package lim
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"limLang/object"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunString(t *testing.T) {
+	interp := New()
+	result, err := interp.RunString("int dataExp = 2 * 4 + 123; dataExp")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	integer, ok := result.(*object.Integer)
+	if !ok || integer.Value != 131 {
+		t.Fatalf("wrong result. got=%T (%+v)", result, result)
+	}
+
+	result, err = interp.RunString("int a = 1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result.Type() != object.NULL_OBJ {
+		t.Fatalf("statements should evaluate to null. got=%T (%+v)", result, result)
+	}
+}
+
+func TestUnicodeSource(t *testing.T) {
+	interp := New()
+	result, err := interp.RunString(`
+	// grüße an alle
+	string grüße = "héllo 世界"
+	int 数 = strings.length(grüße)
+	"${grüße}: ${数}"`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result.Inspect() != "héllo 世界: 8" {
+		t.Fatalf("wrong result. got=%q", result.Inspect())
+	}
+}
+
+func TestRunStringErrors(t *testing.T) {
+	interp := New()
+	_, err := interp.RunString("int a = ;")
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected a ParseError. got=%T (%v)", err, err)
+	}
+
+	_, err = interp.RunString(`string s = "open`)
+	if !errors.As(err, &parseErr) || parseErr.Errors[0] != "line 1, column 12: unterminated string" {
+		t.Fatalf("expected a ParseError for the unterminated string. got=%v", err)
+	}
+
+	_, err = interp.RunString("int a = 1 / 0")
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected a ParseError for division by zero. got=%T (%v)", err, err)
+	}
+
+	_, err = interp.RunString(`fn f(int a) { a + x } f(1)`)
+	var runtimeErr *RuntimeError
+	if !errors.As(err, &runtimeErr) {
+		t.Fatalf("expected a RuntimeError. got=%T (%v)", err, err)
+	}
+	if runtimeErr.Err.Message != "identifier not found: x" {
+		t.Errorf("wrong error message. got=%q", runtimeErr.Err.Message)
+	}
+	if !strings.Contains(err.Error(), "at f (line 1, column 24)") {
+		t.Errorf("error should contain the traceback. got=%q", err.Error())
+	}
+}
+
+func TestIndexErrors(t *testing.T) {
+	interp := New()
+	result, err := interp.RunString("int [] a = []; a[0]")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result.Inspect() != "null" {
+		t.Errorf("out of range index should give null. got=%q", result.Inspect())
+	}
+
+	_, err = interp.RunString("int a = 1; a[0]")
+	var runtimeErr *RuntimeError
+	if !errors.As(err, &runtimeErr) {
+		t.Fatalf("expected a RuntimeError. got=%T (%v)", err, err)
+	}
+	if runtimeErr.Err.Message != "index operator not supported: INTEGER" {
+		t.Errorf("wrong error message. got=%q", runtimeErr.Err.Message)
+	}
+}
+
+func TestNullable(t *testing.T) {
+	interp := New()
+	result, err := interp.RunString(`
+fn find(int key) int? {
+	if key > 1 { return key }
+	return null
+}
+int? a = find(1)
+int? b = find(2)
+if b == null { return -1 }
+(a ?? 10) + b`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if integer, ok := result.(*object.Integer); !ok || integer.Value != 12 {
+		t.Fatalf("wrong result. got=%T (%+v)", result, result)
+	}
+
+	_, err = interp.RunString("int? a = null\na * 2")
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || !strings.HasPrefix(parseErr.Errors[0], "line 2, column 1: `a` might be null") {
+		t.Fatalf("expected a ParseError for using a without checking it. got=%v", err)
+	}
+
+	var out bytes.Buffer
+	interp.SetStdout(&out)
+	if _, err := interp.RunString(`println("a", 1, null)`); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if out.String() != "a 1 null\n" {
+		t.Errorf("wrong output. got=%q", out.String())
+	}
+}
+
+func TestRunFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.lim")
+	if err := os.WriteFile(path, []byte("string s = \"file\"\ns"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	result, err := New().RunFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result.Inspect() != "file" {
+		t.Errorf("wrong result. got=%q", result.Inspect())
+	}
+
+	if _, err := New().RunFile(filepath.Join(t.TempDir(), "missing.lim")); err == nil {
+		t.Errorf("expected an error for a missing file")
+	}
+}
+
+func TestGlobalsAndCall(t *testing.T) {
+	interp := New()
+	interp.SetGlobal("base", &object.Integer{Value: 10})
+	_, err := interp.RunString(`
+	fn add(int a, int b) int {
+		return a + b + base
+	}
+	int total = add(1, 2)
+	`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	total, ok := interp.GetGlobal("total")
+	if !ok || total.Inspect() != "13" {
+		t.Fatalf("wrong value for total. got=%v", total)
+	}
+
+	result, err := interp.Call("add", &object.Integer{Value: 5}, &object.Integer{Value: 6})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result.Inspect() != "21" {
+		t.Errorf("wrong result. got=%q", result.Inspect())
+	}
+
+	if _, err := interp.Call("add", &object.Integer{Value: 5}); err == nil {
+		t.Errorf("expected an arity error")
+	}
+	if _, err := interp.Call("missing"); err == nil {
+		t.Errorf("expected an error for a missing function")
+	}
+	if _, err := interp.Call("total"); err == nil {
+		t.Errorf("expected an error for calling an integer")
+	}
+}
+
+func TestStdoutAndStdin(t *testing.T) {
+	var out bytes.Buffer
+	interp := New()
+	interp.SetStdout(&out)
+	interp.SetStdin(strings.NewReader("lim\nsecond line\n"))
+
+	_, err := interp.RunString(`
+	string name = input()
+	print("hello ", name)
+	`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if out.String() != "hello lim" {
+		t.Errorf("wrong output. got=%q", out.String())
+	}
+
+	result, err := interp.RunString("input()")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result.Inspect() != "second line" {
+		t.Errorf("input() should read the next line. got=%q", result.Inspect())
+	}
+	result, _ = interp.RunString("input()")
+	if result.Type() != object.NULL_OBJ {
+		t.Errorf("input() should return null at the end of input. got=%q", result.Inspect())
+	}
+}
+
+func TestInterpretersDontShareState(t *testing.T) {
+	var out1, out2 bytes.Buffer
+	first, second := New(), New()
+	first.SetStdout(&out1)
+	second.SetStdout(&out2)
+	first.SetCheckedArithmetic(true)
+
+	if _, err := first.RunString(`int a = 1; print("first")`); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, ok := second.GetGlobal("a"); ok {
+		t.Errorf("globals leaked into the second interpreter")
+	}
+	if _, err := second.RunString(`print("second")`); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if out1.String() != "first" || out2.String() != "second" {
+		t.Errorf("output got mixed up. first=%q, second=%q", out1.String(), out2.String())
+	}
+
+	overflow := "int max = 9223372036854775807; max + 1"
+	if _, err := first.RunString(overflow); err == nil {
+		t.Errorf("first interpreter should report overflow")
+	}
+	if _, err := second.RunString(overflow); err != nil {
+		t.Errorf("second interpreter should not check arithmetic. got=%s", err)
+	}
+}
+
+func TestRegisterFunc(t *testing.T) {
+	interp := New()
+	err := interp.RegisterFunc("repeat", func(s string, n int64) (string, error) {
+		if n < 0 {
+			return "", errors.New("count must not be negative")
+		}
+		return strings.Repeat(s, int(n)), nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	result, err := interp.RunString(`repeat("ab", 3)`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result.Inspect() != "ababab" {
+		t.Errorf("wrong result. got=%q", result.Inspect())
+	}
+
+	_, err = interp.RunString(`repeat("ab", -1)`)
+	var runtimeErr *RuntimeError
+	if !errors.As(err, &runtimeErr) || runtimeErr.Err.Message != "count must not be negative" {
+		t.Errorf("expected the Go error as a lim error. got=%v", err)
+	}
+
+	if err := interp.RegisterFunc("bad", "not a function"); err == nil {
+		t.Errorf("expected an error registering a non function")
+	}
+	if _, ok := New().GetGlobal("repeat"); ok {
+		t.Errorf("registered functions leaked into another interpreter")
+	}
+}
+
+func TestRegisterFuncCapabilities(t *testing.T) {
+	called := false
+	load := func(path string) string {
+		called = true
+		return "data from " + path
+	}
+	interp := New()
+	if err := interp.RegisterFunc("load", load, CapFSRead, CapEnv); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, err := interp.RunString(`load("config")`)
+	if !errors.Is(err, ErrPermission) || !strings.Contains(err.Error(), "`load` needs the fs-read capability") {
+		t.Fatalf("expected a permission error. got=%v", err)
+	}
+	interp.SetCapabilities(Capabilities{FSRead: []string{t.TempDir()}})
+	if _, err := interp.RunString(`load("config")`); !errors.Is(err, ErrPermission) {
+		t.Fatalf("every capability should be needed. got=%v", err)
+	}
+	if called {
+		t.Fatalf("load ran without its capabilities")
+	}
+
+	interp.SetCapabilities(Capabilities{FSRead: []string{t.TempDir()}, Env: true})
+	result, err := interp.RunString(`load("config")`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result.Inspect() != "data from config" {
+		t.Errorf("wrong result. got=%q", result.Inspect())
+	}
+}
+
+type account struct {
+	Owner   string
+	Balance int64
+}
+
+func (a *account) Deposit(amount int64) (int64, error) {
+	if amount <= 0 {
+		return a.Balance, errors.New("deposit must be positive")
+	}
+	a.Balance += amount
+	return a.Balance, nil
+}
+
+func TestSetGlobalValue(t *testing.T) {
+	acc := &account{Owner: "ada", Balance: 10}
+	interp := New()
+	if err := interp.SetGlobalValue("acc", acc); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := interp.SetGlobalValue("limit", 100); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	result, err := interp.RunString(`
+	acc.Deposit(5)
+	acc.owner = acc.owner + "!"
+	acc.Balance + limit
+	`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result.Inspect() != "115" {
+		t.Errorf("wrong result. got=%q", result.Inspect())
+	}
+	if acc.Balance != 15 || acc.Owner != "ada!" {
+		t.Errorf("host object was not updated. got=%+v", acc)
+	}
+
+	if _, err := interp.RunString(`acc.Deposit(0)`); err == nil {
+		t.Errorf("expected the method's error")
+	}
+	if err := interp.SetGlobalValue("ch", make(chan int)); err == nil {
+		t.Errorf("expected an error for a value lim can't hold")
+	}
+}
+
+func TestLimits(t *testing.T) {
+	countdown := `
+	fn countdown(int n) int {
+		if (n == 0) {
+			return 0
+		}
+		return countdown(n - 1)
+	}
+	`
+	tests := []struct {
+		limits  Limits
+		input   string
+		wantErr error
+	}{
+		{Limits{MaxSteps: 100}, "countdown(1000)", ErrStepLimit},
+		{Limits{MaxSteps: 100000}, "countdown(1000)", nil},
+		{Limits{MaxDepth: 50}, "countdown(100)", ErrDepthLimit},
+		{Limits{MaxDepth: 50}, "countdown(10)", nil},
+		{Limits{MaxMemory: 1000}, "countdown(1000)", ErrMemoryLimit},
+		{Limits{MaxMemory: 1000}, `string s = "short"`, nil},
+	}
+
+	for _, tt := range tests {
+		interp := New()
+		interp.SetLimits(tt.limits)
+		if _, err := interp.RunString(countdown); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		_, err := interp.RunString(tt.input)
+		if tt.wantErr == nil {
+			if err != nil {
+				t.Errorf("%+v: unexpected error: %s", tt.limits, err)
+			}
+			continue
+		}
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%+v: expected %q. got=%v", tt.limits, tt.wantErr, err)
+		}
+		var runtimeErr *RuntimeError
+		if !errors.As(err, &runtimeErr) {
+			t.Errorf("limit errors should be RuntimeErrors. got=%T", err)
+		}
+	}
+}
+
+func TestLimitsResetForEachRun(t *testing.T) {
+	interp := New()
+	interp.SetLimits(Limits{MaxSteps: 500})
+	_, err := interp.RunString(`fn id(int n) int { return n }`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for i := 0; i < 100; i++ {
+		if _, err := interp.Call("id", &object.Integer{Value: 1}); err != nil {
+			t.Fatalf("call %d: unexpected error: %s", i, err)
+		}
+	}
+}
+
+func TestDefaultDepthLimit(t *testing.T) {
+	_, err := New().RunString(`fn forever(int n) int { return forever(n + 1) } forever(0)`)
+	if !errors.Is(err, ErrDepthLimit) {
+		t.Fatalf("expected the default depth limit to stop the recursion. got=%v", err)
+	}
+	if lines := strings.Count(err.Error(), "\n"); lines > 25 {
+		t.Errorf("traceback should be shortened. got %d lines", lines)
+	}
+}
+
+func TestSetLimitsKeepsDefaultDepth(t *testing.T) {
+	interp := New()
+	interp.SetLimits(Limits{MaxSteps: 100000000})
+	_, err := interp.RunString(`fn forever(int n) int { return forever(n + 1) } forever(0)`)
+	if !errors.Is(err, ErrDepthLimit) {
+		t.Fatalf("expected the default depth limit to stop the recursion. got=%v", err)
+	}
+}
+
+func TestRunStringContext(t *testing.T) {
+	interp := New()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := interp.RunStringContext(ctx, `
+	fn countdown(int n) int {
+		if (n == 0) {
+			return 0
+		}
+		return countdown(n - 1)
+	}
+	countdown(1000)
+	`)
+	if !errors.Is(err, ErrTimeout) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected a timeout caused by the cancelled context. got=%v", err)
+	}
+
+	// the context only applies to that run
+	if _, err := interp.Call("countdown", &object.Integer{Value: 1000}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestCallbacksRespectLimits(t *testing.T) {
+	interp := New()
+	interp.SetLimits(Limits{MaxSteps: 1000})
+	err := interp.RegisterFunc("times", func(n int64, f func(int64) int64) int64 {
+		var total int64
+		for i := int64(0); i < n; i++ {
+			total += f(i)
+		}
+		return total
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	_, err = interp.RunString(`
+	fn double(int n) int { return n * 2 }
+	times(1000, double)
+	`)
+	if !errors.Is(err, ErrStepLimit) {
+		t.Fatalf("expected the callbacks to use up the step budget. got=%v", err)
+	}
+}
+
+func TestCapabilitiesDenyByDefault(t *testing.T) {
+	tests := []struct {
+		input      string
+		capability string
+	}{
+		{`now()`, "clock"},
+		{`random(10)`, "random"},
+		{`getenv("HOME")`, "env"},
+		{`exec("echo", "hi")`, "exec"},
+		{`read_file("main.lim")`, "fs-read"},
+		{`write_file("out.txt", "x")`, "fs-write"},
+	}
+
+	for _, tt := range tests {
+		_, err := New().RunString(tt.input)
+		if !errors.Is(err, ErrPermission) {
+			t.Errorf("%s: expected a permission error. got=%v", tt.input, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), "the "+tt.capability+" capability") {
+			t.Errorf("%s: error should name the %s capability. got=%q", tt.input, tt.capability, err.Error())
+		}
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	allowed, other := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(allowed, "in.txt"), []byte("inside"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(other, "secret.txt"), []byte("outside"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	interp := New()
+	interp.SetCapabilities(Capabilities{FSRead: []string{allowed}, FSWrite: []string{allowed}, Clock: true})
+	interp.SetGlobal("allowed", &object.String{Value: allowed})
+	interp.SetGlobal("other", &object.String{Value: other})
+
+	result, err := interp.RunString(`read_file(allowed + "/in.txt")`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result.Inspect() != "inside" {
+		t.Errorf("wrong file contents. got=%q", result.Inspect())
+	}
+	if _, err := interp.RunString(`now()`); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	denied := []string{
+		`read_file(other + "/secret.txt")`,
+		`read_file(allowed + "/../" + "secret.txt")`,
+		`write_file(other + "/out.txt", "x")`,
+		`getenv("HOME")`,
+	}
+	for _, input := range denied {
+		if _, err := interp.RunString(input); !errors.Is(err, ErrPermission) {
+			t.Errorf("%s: expected a permission error. got=%v", input, err)
+		}
+	}
+
+	if _, err := interp.RunString(`write_file(allowed + "/out.txt", "written")`); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	data, err := os.ReadFile(filepath.Join(allowed, "out.txt"))
+	if err != nil || string(data) != "written" {
+		t.Errorf("file was not written. got=%q, err=%v", data, err)
+	}
+}
+
+func TestSymlinksDontEscapeRoots(t *testing.T) {
+	allowed, other := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(other, "secret.txt"), []byte("outside"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(other, filepath.Join(allowed, "link")); err != nil {
+		t.Skipf("can't create symlinks: %s", err)
+	}
+
+	interp := New()
+	interp.SetCapabilities(Capabilities{FSRead: []string{allowed}})
+	interp.SetGlobal("allowed", &object.String{Value: allowed})
+	if _, err := interp.RunString(`read_file(allowed + "/link/secret.txt")`); !errors.Is(err, ErrPermission) {
+		t.Errorf("expected a permission error. got=%v", err)
+	}
+}
+
+func TestFormattedOutput(t *testing.T) {
+	var out bytes.Buffer
+	interp := New()
+	interp.SetStdout(&out)
+	result, err := interp.RunString(`
+	println("total:", 3, true)
+	printf("%-5s|%6.2f|", "pi", math.pi)
+	println()
+	print("a", "b")
+	`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result.Type() != object.NULL_OBJ {
+		t.Errorf("print should return null. got=%s", result.Type())
+	}
+	expected := "total: 3 true\npi   |  3.14|\nab"
+	if out.String() != expected {
+		t.Errorf("wrong output. got=%q, want=%q", out.String(), expected)
+	}
+}
@@ -1,10 +1,10 @@
 package main
 
 import (
-	"limLang/evaluator"
-	"limLang/lexer"
-	"limLang/object"
-	"limLang/parser"
+	"flag"
+	"fmt"
+	"limLang/doc"
+	"limLang/lim"
 	"log"
 	"os"
 )
@@ -16,26 +16,60 @@ func main() {
 	// }
 	// fmt.Printf("Hello %s! This is  ling lang!\n", user.Username)
 
-	if len(os.Args) < 1 {
-		log.Fatalf("Usage: %s <file-path>", os.Args[0])
+	checked := flag.Bool("checked", false, "report integer overflow as an error instead of wrapping around")
+	sandbox := flag.Bool("sandbox", false, "don't let the script touch files, the environment, other programs, the clock or randomness")
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		log.Fatalf("Usage: %s [-checked] [-sandbox] <file-path>\n       %s doc [-html] <file-or-dir> [name]", os.Args[0], os.Args[0])
+	}
+	if flag.Arg(0) == "doc" {
+		if err := runDoc(flag.Args()[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
 	}
 
 	// Get the file path from the command line arguments
-	filePath := os.Args[1]
+	filePath := flag.Arg(0)
 
-	data, err := os.ReadFile(filePath)
-	if err != nil {
-		log.Fatalf("Failed to read file: %s", err)
+	interp := lim.New()
+	interp.SetCheckedArithmetic(*checked)
+	if !*sandbox {
+		interp.SetCapabilities(lim.AllCapabilities())
 	}
+	if _, err := interp.RunFile(filePath); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
 
-	// Convert byte slice to string
-	fileContent := string(data)
+// runDoc is `lim doc`, it prints the documentation of a library as Markdown
+// or HTML, or just the function or constant called name if one is given
+func runDoc(args []string) error {
+	flags := flag.NewFlagSet("doc", flag.ExitOnError)
+	html := flags.Bool("html", false, "write a standalone HTML page instead of Markdown")
+	flags.Parse(args)
+	if flags.NArg() < 1 || flags.NArg() > 2 {
+		return fmt.Errorf("Usage: %s doc [-html] <file-or-dir> [name]", os.Args[0])
+	}
 
-	// Print the file content
-	// fmt.Println(fileContent)
-	l := lexer.New(fileContent)
-	p := parser.New(l)
-	program := p.ParseProgram()
-	env := object.NewEnvironment()
-	evaluator.Eval(program, env)
+	lib, err := doc.Load(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if name := flags.Arg(1); name != "" {
+		if f := lib.Lookup(name); f != nil {
+			return f.Text(os.Stdout)
+		}
+		if c := lib.LookupConst(name); c != nil {
+			return c.Text(os.Stdout)
+		}
+		return fmt.Errorf("no function or constant %s in %s", name, flags.Arg(0))
+	}
+	if *html {
+		return lib.HTML(os.Stdout)
+	}
+	return lib.Markdown(os.Stdout)
 }
@@ -0,0 +1,118 @@
+package object
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+)
+
+// Capability is a power over the world outside the interpreter that a
+// builtin needs before it can be called.
+type Capability string
+
+const (
+	CapFSRead  Capability = "fs-read"
+	CapFSWrite Capability = "fs-write"
+	CapExec    Capability = "exec"
+	CapEnv     Capability = "env"
+	CapClock   Capability = "clock"
+	CapRandom  Capability = "random"
+)
+
+// ErrPermission is wrapped by the errors of builtins called without the
+// capabilities they need.
+var ErrPermission = errors.New("permission denied")
+
+// Capabilities is what the scripts of an interpreter are allowed to do, the
+// zero value allows nothing.
+type Capabilities struct {
+	// directories whose files may be read or written, including their
+	// subdirectories
+	FSRead  []string
+	FSWrite []string
+
+	Exec   bool
+	Env    bool
+	Clock  bool
+	Random bool
+}
+
+// AllCapabilities allows everything, for running trusted scripts.
+func AllCapabilities() Capabilities {
+	root := string(filepath.Separator)
+	return Capabilities{
+		FSRead:  []string{root},
+		FSWrite: []string{root},
+		Exec:    true,
+		Env:     true,
+		Clock:   true,
+		Random:  true,
+	}
+}
+
+func (c *Capabilities) Has(capability Capability) bool {
+	switch capability {
+	case CapFSRead:
+		return len(c.FSRead) > 0
+	case CapFSWrite:
+		return len(c.FSWrite) > 0
+	case CapExec:
+		return c.Exec
+	case CapEnv:
+		return c.Env
+	case CapClock:
+		return c.Clock
+	case CapRandom:
+		return c.Random
+	}
+	return false
+}
+
+// CanRead reports whether path lies inside one of the FSRead roots.
+func (c *Capabilities) CanRead(path string) bool {
+	return insideRoots(path, c.FSRead)
+}
+
+// CanWrite reports whether path lies inside one of the FSWrite roots.
+func (c *Capabilities) CanWrite(path string) bool {
+	return insideRoots(path, c.FSWrite)
+}
+
+func insideRoots(path string, roots []string) bool {
+	path, err := resolvePath(path)
+	if err != nil {
+		return false
+	}
+	for _, root := range roots {
+		root, err := resolvePath(root)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			continue
+		}
+		if rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePath makes path absolute and follows symlinks so a link inside a
+// root can't be used to reach files outside of it. Files that don't exist
+// yet are resolved through their directory.
+func resolvePath(path string) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved, nil
+	}
+	dir, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return path, nil
+	}
+	return filepath.Join(dir, filepath.Base(path)), nil
+}
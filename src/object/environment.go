@@ -2,12 +2,26 @@ package object
 
 func NewEnvironment() *Environment {
 	s := make(map[string]Object)
-	return &Environment{store: s, outer: nil}
+	t := make(map[string]ObjectType)
+	return &Environment{store: s, types: t, nullable: map[string]bool{}, constants: map[string]bool{}, outer: nil, runtime: &Runtime{}}
 }
 
 type Environment struct {
 	store map[string]Object
-	outer *Environment
+	// declared type of the variables that were created with one, like `int x`
+	types map[string]ObjectType
+	// typed variables declared like `int? x`, they can also hold null
+	nullable map[string]bool
+	// variables declared like `const int x`, they can't be assigned again
+	constants map[string]bool
+	outer     *Environment
+
+	runtime *Runtime
+}
+
+// Runtime returns the settings of the interpreter this environment belongs to
+func (e *Environment) Runtime() *Runtime {
+	return e.runtime
 }
 
 func (e *Environment) Get(name string) (Object, bool) {
@@ -20,11 +34,87 @@ func (e *Environment) Get(name string) (Object, bool) {
 
 func (e *Environment) Set(name string, val Object) Object {
 	e.store[name] = val
+	delete(e.types, name)
+	delete(e.nullable, name)
+	delete(e.constants, name)
+	return val
+}
+
+// Declare creates a variable in this scope that can only ever hold values of
+// type t.
+func (e *Environment) Declare(name string, val Object, t ObjectType) Object {
+	e.store[name] = val
+	e.types[name] = t
+	delete(e.nullable, name)
+	delete(e.constants, name)
 	return val
 }
 
+// DeclareNullable is Declare for variables like `int? x`, they hold values
+// of type t or null.
+func (e *Environment) DeclareNullable(name string, val Object, t ObjectType) Object {
+	e.Declare(name, val, t)
+	e.nullable[name] = true
+	return val
+}
+
+// Nullable tells if the variable name refers to was declared nullable.
+func (e *Environment) Nullable(name string) bool {
+	if _, ok := e.store[name]; ok {
+		return e.nullable[name]
+	}
+	if e.outer != nil {
+		return e.outer.Nullable(name)
+	}
+	return false
+}
+
+// MakeConstant turns the variable name in this scope into a constant, it
+// keeps its value until it is declared again.
+func (e *Environment) MakeConstant(name string) {
+	e.constants[name] = true
+}
+
+// Constant tells if the variable name refers to is a constant.
+func (e *Environment) Constant(name string) bool {
+	if _, ok := e.store[name]; ok {
+		return e.constants[name]
+	}
+	if e.outer != nil {
+		return e.outer.Constant(name)
+	}
+	return false
+}
+
+// TypeOf returns the declared type of the variable name refers to, ok is
+// false when the variable doesn't exist or was created without a type.
+func (e *Environment) TypeOf(name string) (ObjectType, bool) {
+	if _, ok := e.store[name]; ok {
+		t, ok := e.types[name]
+		return t, ok
+	}
+	if e.outer != nil {
+		return e.outer.TypeOf(name)
+	}
+	return "", false
+}
+
+// Assign replaces the value of an existing variable in the scope it was
+// declared in. It returns false if there is no such variable.
+func (e *Environment) Assign(name string, val Object) bool {
+	if _, ok := e.store[name]; ok {
+		e.store[name] = val
+		return true
+	}
+	if e.outer != nil {
+		return e.outer.Assign(name, val)
+	}
+	return false
+}
+
 func NewEnclosedEnviornment(outer *Environment) *Environment {
 	env := NewEnvironment()
 	env.outer = outer
+	env.runtime = outer.runtime
 	return env
 }
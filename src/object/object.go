@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"fmt"
 	"limLang/ast"
+	"strconv"
 	"strings"
 )
 
 const (
 	INTEGER_OBJ      = "INTEGER"
+	FLOAT_OBJ        = "FLOAT"
 	BOOLEAN_OBJ      = "BOOLEAN"
 	STRING_OBJ       = "STRING"
 	ARRAY_OBJ        = "ARRAY"
@@ -17,6 +19,8 @@ const (
 	RETURN_VALUE_OBJ = "RETURN_VALUE"
 	BUILTIN_OBJ      = "BUILTIN"
 	ERROR_OBJ        = "ERROR"
+	HOST_OBJ         = "HOST"
+	MODULE_OBJ       = "MODULE"
 )
 
 type ObjectType string
@@ -28,11 +32,70 @@ type Object interface {
 
 type Error struct {
 	Message string
+
+	// where the error happened in the source, zero if we don't know
+	Line   int
+	Column int
+
+	// lim function calls the error passed through, innermost call first
+	Stack []StackFrame
+
+	// the Go error behind it, like ErrStepLimit, nil for errors made by
+	// the script
+	Cause error
 }
 
 func (e *Error) Type() ObjectType { return ERROR_OBJ }
 func (e *Error) Inspect() string  { return "ERROR: " + e.Message }
 
+// Error makes lim errors usable as Go errors when they are handed to Go code
+func (e *Error) Error() string { return e.Message }
+
+func (e *Error) Unwrap() error { return e.Cause }
+
+// deep recursion leaves thousands of identical frames, Traceback only shows
+// the ends of stacks longer than this
+const maxTracebackFrames = 20
+
+// Traceback renders the error along with the chain of calls that led to it.
+func (e *Error) Traceback() string {
+	var out bytes.Buffer
+	if e.Line > 0 {
+		out.WriteString(fmt.Sprintf("error at line %d, column %d: ", e.Line, e.Column))
+	} else {
+		out.WriteString("error: ")
+	}
+	out.WriteString(e.Message)
+	for i, frame := range e.Stack {
+		if len(e.Stack) > maxTracebackFrames {
+			half := maxTracebackFrames / 2
+			if i == half {
+				out.WriteString(fmt.Sprintf("\n    ... %d more calls", len(e.Stack)-maxTracebackFrames))
+			}
+			if i >= half && i < len(e.Stack)-half {
+				continue
+			}
+		}
+		out.WriteString("\n    at ")
+		out.WriteString(frame.String())
+	}
+	return out.String()
+}
+
+type StackFrame struct {
+	FuncName string
+	Line     int
+	Column   int
+}
+
+func (sf StackFrame) String() string {
+	if sf.Line == 0 {
+		// called from Go or by a builtin
+		return sf.FuncName
+	}
+	return fmt.Sprintf("%s (line %d, column %d)", sf.FuncName, sf.Line, sf.Column)
+}
+
 type Integer struct {
 	Value int64
 }
@@ -40,6 +103,21 @@ type Integer struct {
 func (i *Integer) Inspect() string  { return fmt.Sprintf("%d", i.Value) }
 func (i *Integer) Type() ObjectType { return INTEGER_OBJ }
 
+type Float struct {
+	Value float64
+}
+
+// Inspect always shows a dot or an exponent so floats with whole values
+// don't look like integers
+func (f *Float) Inspect() string {
+	str := strconv.FormatFloat(f.Value, 'g', -1, 64)
+	if !strings.ContainsAny(str, ".eIN") {
+		str += ".0"
+	}
+	return str
+}
+func (f *Float) Type() ObjectType { return FLOAT_OBJ }
+
 type Boolean struct {
 	Value bool
 }
@@ -95,9 +173,16 @@ func (f *Function) Inspect() string {
 	return out.String()
 }
 
-type BuiltinFunction func(args ...Object) Object
+// BuiltinFunction gets the environment of the caller so builtins can reach
+// the interpreter they run in, for example to write to its Stdout.
+type BuiltinFunction func(env *Environment, args ...Object) Object
 type Builtin struct {
-	Fn BuiltinFunction
+	Name string
+	Fn   BuiltinFunction
+
+	// checked before Fn is called, a builtin missing any of them fails
+	// with a permission error
+	Requires []Capability
 }
 
 func (b *Builtin) Type() ObjectType { return BUILTIN_OBJ }
@@ -119,3 +204,22 @@ func (ao *Array) Inspect() string {
 	out.WriteString("]")
 	return out.String()
 }
+
+// Host wraps a Go struct pointer or map handed to scripts by the program
+// embedding lim. Scripts use its exported fields and methods with `obj.name`.
+type Host struct {
+	Value interface{}
+}
+
+func (h *Host) Type() ObjectType { return HOST_OBJ }
+func (h *Host) Inspect() string  { return fmt.Sprintf("%v", h.Value) }
+
+// Module groups builtins under a name, scripts use them like
+// `strings.split(s, ",")`.
+type Module struct {
+	Name    string
+	Members map[string]Object
+}
+
+func (m *Module) Type() ObjectType { return MODULE_OBJ }
+func (m *Module) Inspect() string  { return "module " + m.Name }
@@ -0,0 +1,125 @@
+package object
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// The errors a run is aborted with when it goes over one of its Limits. The
+// lim error reporting it wraps them, so hosts can tell them apart with
+// errors.Is.
+var (
+	ErrStepLimit   = errors.New("step limit exceeded")
+	ErrTimeout     = errors.New("execution timed out")
+	ErrDepthLimit  = errors.New("call depth limit exceeded")
+	ErrMemoryLimit = errors.New("memory limit exceeded")
+)
+
+// Limits bound what a single run may use, zero means no limit.
+type Limits struct {
+	// nodes evaluated
+	MaxSteps int64
+	// nested calls of lim functions
+	MaxDepth int
+	// rough number of bytes of objects created
+	MaxMemory int64
+}
+
+// Runtime holds the settings of a single interpreter. Every environment
+// created for a run shares the same Runtime, so two interpreters never see
+// each others settings.
+type Runtime struct {
+	// report integer overflow on + - * / and negation as an error instead
+	// of silently wrapping around
+	CheckedArithmetic bool
+
+	// where builtins like print write to and read from, nil means
+	// os.Stdout and os.Stdin
+	Stdout io.Writer
+	Stdin  io.Reader
+
+	Limits Limits
+	// what builtins may do, nothing unless it is granted
+	Capabilities Capabilities
+	// cancelling it stops the run, nil means it runs until it's done
+	Context context.Context
+
+	// usage of the current run
+	steps  int64
+	depth  int
+	memory int64
+	// nested Begin calls, usage is only reset by the outermost one
+	running int
+}
+
+func (rt *Runtime) Out() io.Writer {
+	if rt.Stdout == nil {
+		return os.Stdout
+	}
+	return rt.Stdout
+}
+
+func (rt *Runtime) In() io.Reader {
+	if rt.Stdin == nil {
+		return os.Stdin
+	}
+	return rt.Stdin
+}
+
+// Begin starts a run, every run gets the full Limits. Runs started while
+// another one is going on (like a callback made by a builtin) count against
+// the outer run instead.
+func (rt *Runtime) Begin() {
+	if rt.running == 0 {
+		rt.steps, rt.depth, rt.memory = 0, 0, 0
+	}
+	rt.running++
+}
+
+func (rt *Runtime) End() {
+	rt.running--
+}
+
+// how often Step looks at the context, checking it is a lot slower than
+// counting
+const contextCheckInterval = 64
+
+// Step counts one evaluation step.
+func (rt *Runtime) Step() error {
+	rt.steps++
+	if rt.Limits.MaxSteps > 0 && rt.steps > rt.Limits.MaxSteps {
+		return fmt.Errorf("%w: more than %d steps", ErrStepLimit, rt.Limits.MaxSteps)
+	}
+	if rt.Context != nil && rt.steps%contextCheckInterval == 0 {
+		if err := rt.Context.Err(); err != nil {
+			return fmt.Errorf("%w: %w", ErrTimeout, err)
+		}
+	}
+	return nil
+}
+
+// EnterCall is called before a lim function runs and LeaveCall once it has
+// returned. LeaveCall must not be called if EnterCall failed.
+func (rt *Runtime) EnterCall() error {
+	if rt.Limits.MaxDepth > 0 && rt.depth >= rt.Limits.MaxDepth {
+		return fmt.Errorf("%w: more than %d nested calls", ErrDepthLimit, rt.Limits.MaxDepth)
+	}
+	rt.depth++
+	return nil
+}
+
+func (rt *Runtime) LeaveCall() {
+	rt.depth--
+}
+
+// Allocate records that an object of about size bytes was created.
+func (rt *Runtime) Allocate(size int64) error {
+	rt.memory += size
+	if rt.Limits.MaxMemory > 0 && rt.memory > rt.Limits.MaxMemory {
+		return fmt.Errorf("%w: more than %d bytes allocated", ErrMemoryLimit, rt.Limits.MaxMemory)
+	}
+	return nil
+}
@@ -0,0 +1,471 @@
+package optimizer
+
+import (
+	"fmt"
+	"limLang/ast"
+	"limLang/token"
+	"strings"
+)
+
+// nullChecker makes sure values of nullable variables (`int? x`) are
+// checked before they are used. A nullable value can be compared with ==
+// and !=, given a default with ??, used with ?. or passed on to something
+// that is nullable itself. Anything else is an error unless the variable is
+// known not to be null at that point:
+//
+//	if x != null { x + 1 }          // fine
+//	if x == null { return }; x + 1  // fine
+//	x + 1                           // error
+//
+// Only the four scalar types can be nullable, so there are no nullable
+// arrays or functions to worry about. Variables from an earlier program
+// run in the same interpreter aren't known here and are left to the
+// evaluator.
+type nullChecker struct {
+	errors *[]string
+	scope  *nullScope
+	// the function whose body is being checked, nil at the top level
+	fn *ast.FunctionStatement
+	// variables some function assigns that aren't its own, any call can
+	// make them null again
+	reassigned map[string]bool
+}
+
+type nullScope struct {
+	// only set for function bodies and the top level, see declare
+	vars map[string]*nullVar
+	// variables declared in an outer scope that were checked (true) or
+	// assigned something that might be null (false) in this one
+	narrowed map[string]bool
+	// the scope of a function body, checks done outside of it don't count
+	// inside since the function can run at any time
+	function bool
+	outer    *nullScope
+}
+
+type nullVar struct {
+	typ      string // the type keyword, for messages
+	nullable bool
+	fn       *ast.FunctionStatement // set for functions
+}
+
+func newNullScope(outer *nullScope) *nullScope {
+	return &nullScope{vars: map[string]*nullVar{}, narrowed: map[string]bool{}, outer: outer}
+}
+
+// checkNulls reports the nullable values in program that are used without
+// checking them
+func (o *Optimizer) checkNulls(program *ast.Program) {
+	c := &nullChecker{errors: &o.errors, scope: newNullScope(nil), reassigned: map[string]bool{}}
+	reassignedByFunctions(program.Statements, c.reassigned)
+	c.statements(program.Statements)
+}
+
+// reassignedByFunctions adds the variables the functions in stmts assign to
+// out, leaving out their parameters and the variables they declare
+func reassignedByFunctions(stmts []ast.Statement, out map[string]bool) {
+	sameEnv(stmts, func(stmt ast.Statement) {
+		fn, ok := stmt.(*ast.FunctionStatement)
+		if !ok || fn.Body == nil {
+			return
+		}
+		local := map[string]bool{}
+		for _, param := range fn.Parameters {
+			local[param.Value] = true
+		}
+		sameEnv(fn.Body.Statements, func(stmt ast.Statement) {
+			if name := declaredName(stmt); name != "" {
+				local[name] = true
+			}
+		})
+		sameEnv(fn.Body.Statements, func(stmt ast.Statement) {
+			if r, ok := stmt.(*ast.ReassignStatement); ok && !local[r.Name.Value] {
+				out[r.Name.Value] = true
+			}
+		})
+		reassignedByFunctions(fn.Body.Statements, out)
+	})
+}
+
+// sameEnv calls visit with stmts and the statements in their if bodies and
+// blocks, but not the ones in functions since those get an environment of
+// their own
+func sameEnv(stmts []ast.Statement, visit func(ast.Statement)) {
+	for _, stmt := range stmts {
+		visit(stmt)
+		switch stmt := stmt.(type) {
+		case *ast.BlockStatement:
+			sameEnv(stmt.Statements, visit)
+		case *ast.IfStatement:
+			for ifCase := stmt; ifCase != nil; ifCase = ifCase.NextCase {
+				if ifCase.Consequence != nil {
+					sameEnv(ifCase.Consequence.Statements, visit)
+				}
+			}
+		}
+	}
+}
+
+func declaredName(stmt ast.Statement) string {
+	switch stmt := stmt.(type) {
+	case *ast.IntStatement:
+		return stmt.Name.Value
+	case *ast.FloatStatement:
+		return stmt.Name.Value
+	case *ast.BoolStatement:
+		return stmt.Name.Value
+	case *ast.StringStatement:
+		return stmt.Name.Value
+	case *ast.ArrayLiteral:
+		return stmt.Name.Value
+	case *ast.FunctionStatement:
+		return stmt.FnName
+	}
+	return ""
+}
+
+func (c *nullChecker) errorf(tok token.Token, format string, a ...interface{}) {
+	msg := fmt.Sprintf("line %d, column %d: ", tok.Line, tok.Column) + fmt.Sprintf(format, a...)
+	*c.errors = append(*c.errors, msg)
+}
+
+// lookup finds the declaration of name and tells if it might be null where
+// we are now
+func (c *nullChecker) lookup(name string) (*nullVar, bool) {
+	checked, known := false, false
+	for s := c.scope; s != nil; s = s.outer {
+		if v, ok := s.narrowed[name]; ok && !known {
+			checked, known = v, true
+		}
+		if v, ok := s.vars[name]; ok {
+			return v, v.nullable && !checked
+		}
+		if s.function {
+			// narrowing outside of the function doesn't apply, keep
+			// looking for the declaration only
+			known = true
+		}
+	}
+	return nil, false
+}
+
+// declare adds name to the scope of the function we are in, if bodies and
+// blocks run in the environment around them so they can't hold variables
+// of their own
+func (c *nullChecker) declare(name string, v *nullVar) {
+	for s := c.scope; ; s = s.outer {
+		delete(s.narrowed, name)
+		if s.function || s.outer == nil {
+			s.vars[name] = v
+			return
+		}
+	}
+}
+
+// assigned records that name was given a value that might be null or not
+func (c *nullChecker) assigned(name string, mightBeNull bool) {
+	if !mightBeNull {
+		c.scope.narrowed[name] = true
+		return
+	}
+	// whatever was checked about it before doesn't hold anymore, in any
+	// of the scopes up to the declaration
+	for s := c.scope; s != nil; s = s.outer {
+		if _, ok := s.vars[name]; ok {
+			delete(s.narrowed, name)
+			return
+		}
+		s.narrowed[name] = false
+		if s.function {
+			return
+		}
+	}
+}
+
+func (c *nullChecker) statements(stmts []ast.Statement) {
+	for _, stmt := range stmts {
+		c.statement(stmt)
+	}
+}
+
+func (c *nullChecker) block(block *ast.BlockStatement) {
+	if block == nil {
+		return
+	}
+	c.scope = newNullScope(c.scope)
+	c.statements(block.Statements)
+	c.scope = c.scope.outer
+}
+
+func (c *nullChecker) statement(stmt ast.Statement) {
+	switch stmt := stmt.(type) {
+	case *ast.ExpressionStatement:
+		// the value of an expression statement isn't used by anything,
+		// the REPL just shows it
+		c.visit(stmt.Expression)
+	case *ast.IntStatement:
+		c.declaration(stmt.Token, stmt.Name, stmt.Value, stmt.Nullable)
+	case *ast.FloatStatement:
+		c.declaration(stmt.Token, stmt.Name, stmt.Value, stmt.Nullable)
+	case *ast.BoolStatement:
+		c.declaration(stmt.Token, stmt.Name, stmt.Value, stmt.Nullable)
+	case *ast.StringStatement:
+		c.declaration(stmt.Token, stmt.Name, stmt.Value, stmt.Nullable)
+	case *ast.ReassignStatement:
+		v, _ := c.lookup(stmt.Name.Value)
+		if v == nil || !v.nullable {
+			c.value(stmt.Value, stmt.Name.Value, v)
+			return
+		}
+		c.visit(stmt.Value)
+		c.assigned(stmt.Name.Value, c.mightBeNull(stmt.Value))
+	case *ast.MemberAssignStatement:
+		c.use(stmt.Target.Object)
+		c.use(stmt.Value)
+	case *ast.ReturnStatement:
+		if stmt.ReturnValue == nil {
+			return
+		}
+		if c.fn == nil || c.fn.ReturnType.Type == "" || c.fn.NullableReturn {
+			c.visit(stmt.ReturnValue)
+		} else {
+			c.use(stmt.ReturnValue)
+		}
+	case *ast.ArrayLiteral:
+		for _, el := range stmt.Elements {
+			c.use(el)
+		}
+		if stmt.Value != nil {
+			c.use(stmt.Value)
+		}
+		c.declare(stmt.Name.Value, &nullVar{typ: stmt.Type.Literal})
+	case *ast.IndexExpression:
+		c.use(stmt.Ident)
+		c.use(stmt.Index)
+	case *ast.BlockStatement:
+		c.block(stmt)
+	case *ast.FunctionStatement:
+		c.function(stmt)
+	case *ast.IfStatement:
+		c.ifStatement(stmt)
+	}
+}
+
+func (c *nullChecker) declaration(typ token.Token, name *ast.Identifier, value ast.Expression, nullable bool) {
+	v := &nullVar{typ: typ.Literal, nullable: nullable}
+	if nullable {
+		c.visit(value)
+	} else {
+		c.value(value, name.Value, v)
+	}
+	c.declare(name.Value, v)
+	if nullable && !c.mightBeNull(value) {
+		c.assigned(name.Value, false)
+	}
+}
+
+// value checks the value given to a variable that can't be null, v is nil
+// when we don't know the variable
+func (c *nullChecker) value(value ast.Expression, name string, v *nullVar) {
+	if n, ok := value.(*ast.Null); ok && v != nil && v.typ != "" {
+		c.errorf(n.Token, "cannot use null as %s, declare `%s` as %s? to allow it", v.typ, name, v.typ)
+		return
+	}
+	c.use(value)
+}
+
+func (c *nullChecker) function(fn *ast.FunctionStatement) {
+	// declared first so the function can call itself
+	c.declare(fn.FnName, &nullVar{fn: fn})
+
+	outerFn := c.fn
+	c.fn = fn
+	c.scope = newNullScope(c.scope)
+	c.scope.function = true
+	for _, param := range fn.Parameters {
+		c.declare(param.Value, &nullVar{typ: param.HoldsVarType.Literal, nullable: param.Nullable && !param.Variadic})
+	}
+	if fn.Body != nil {
+		c.statements(fn.Body.Statements)
+	}
+	c.scope = c.scope.outer
+	c.fn = outerFn
+}
+
+// ifStatement narrows the variables the conditions check: `x != null`
+// in the case itself, `x == null` in the cases after it and, if the case
+// returns, after the whole statement.
+func (c *nullChecker) ifStatement(root *ast.IfStatement) {
+	checked := []string{}
+	for ifCase := root; ifCase != nil; ifCase = ifCase.NextCase {
+		c.scope = newNullScope(c.scope)
+		for _, name := range checked {
+			c.scope.narrowed[name] = true
+		}
+		if ifCase.Condition != nil {
+			c.use(ifCase.Condition)
+		}
+		name, notNull := c.nullCheck(ifCase.Condition)
+		if name != "" && notNull {
+			c.scope.narrowed[name] = true
+		} else if name != "" {
+			checked = append(checked, name)
+		}
+		c.block(ifCase.Consequence)
+		c.scope = c.scope.outer
+	}
+
+	if name, notNull := c.nullCheck(root.Condition); name != "" && !notNull && returns(root.Consequence) {
+		c.assigned(name, false)
+	}
+}
+
+// nullCheck tells if cond is `x != null` (notNull is true) or `x == null`
+// with x a nullable variable, name is empty if it is neither
+func (c *nullChecker) nullCheck(cond ast.Expression) (name string, notNull bool) {
+	ie, ok := cond.(*ast.InfixExpression)
+	if !ok || (ie.Operator != "==" && ie.Operator != "!=") {
+		return "", false
+	}
+	ident, ok := ie.Left.(*ast.Identifier)
+	other := ie.Right
+	if !ok {
+		ident, ok = ie.Right.(*ast.Identifier)
+		other = ie.Left
+	}
+	if _, isNull := other.(*ast.Null); !ok || !isNull {
+		return "", false
+	}
+	if v, _ := c.lookup(ident.Value); v == nil || !v.nullable {
+		return "", false
+	}
+	return ident.Value, ie.Operator == "!="
+}
+
+// returns tells if block always ends with a return
+func returns(block *ast.BlockStatement) bool {
+	if block == nil || len(block.Statements) == 0 {
+		return false
+	}
+	_, ok := block.Statements[len(block.Statements)-1].(*ast.ReturnStatement)
+	return ok
+}
+
+// use checks exp where its value must not be null
+func (c *nullChecker) use(exp ast.Expression) {
+	c.visit(exp)
+	if !c.mightBeNull(exp) {
+		return
+	}
+	switch exp := exp.(type) {
+	case *ast.Identifier:
+		c.errorf(exp.Token, "`%s` might be null, check it with `if %s != null` or give a default with `%s ?? value`", exp.Value, exp.Value, exp.Value)
+	case *ast.Null:
+		c.errorf(exp.Token, "cannot use null here")
+	default:
+		c.errorf(tokenOf(exp), "`%s` might be null, check it first or give a default with `?? value`", strings.TrimSpace(exp.String()))
+	}
+}
+
+// visit checks the expressions inside exp, exp itself may be null
+func (c *nullChecker) visit(exp ast.Expression) {
+	switch exp := exp.(type) {
+	case *ast.PrefixExpression:
+		c.use(exp.Right)
+	case *ast.InfixExpression:
+		switch exp.Operator {
+		case "==", "!=", "??":
+			c.visit(exp.Left)
+			c.visit(exp.Right)
+		default:
+			c.use(exp.Left)
+			c.use(exp.Right)
+		}
+	case *ast.MemberExpression:
+		if exp.Optional {
+			c.visit(exp.Object)
+		} else {
+			c.use(exp.Object)
+		}
+	case *ast.CallExpression:
+		c.use(exp.Function)
+		// builtins handle null themselves, only the parameters of lim
+		// functions we know are checked
+		params := c.parameters(exp.Function)
+		for i, arg := range exp.Arguments {
+			if param := paramAt(params, i); param != nil && (!param.Nullable || param.Variadic) {
+				c.use(arg)
+			} else {
+				c.visit(arg)
+			}
+		}
+		// the function might have assigned null to any of these
+		for name := range c.reassigned {
+			c.assigned(name, true)
+		}
+	case *ast.InterpolatedString:
+		for _, part := range exp.Parts {
+			c.use(part)
+		}
+	}
+}
+
+// parameters are the parameters of the lim function fn refers to, nil if
+// it isn't one we know
+func (c *nullChecker) parameters(fn ast.Expression) []*ast.Identifier {
+	ident, ok := fn.(*ast.Identifier)
+	if !ok {
+		return nil
+	}
+	if v, _ := c.lookup(ident.Value); v != nil && v.fn != nil {
+		return v.fn.Parameters
+	}
+	return nil
+}
+
+func paramAt(params []*ast.Identifier, i int) *ast.Identifier {
+	if i < len(params) {
+		return params[i]
+	}
+	if len(params) > 0 && params[len(params)-1].Variadic {
+		return params[len(params)-1]
+	}
+	return nil
+}
+
+func (c *nullChecker) mightBeNull(exp ast.Expression) bool {
+	switch exp := exp.(type) {
+	case *ast.Null:
+		return true
+	case *ast.Identifier:
+		_, mightBeNull := c.lookup(exp.Value)
+		return mightBeNull
+	case *ast.InfixExpression:
+		return exp.Operator == "??" && c.mightBeNull(exp.Right)
+	case *ast.MemberExpression:
+		return exp.Optional
+	case *ast.CallExpression:
+		if ident, ok := exp.Function.(*ast.Identifier); ok {
+			v, _ := c.lookup(ident.Value)
+			return v != nil && v.fn != nil && v.fn.NullableReturn
+		}
+	}
+	return false
+}
+
+// tokenOf is where an expression that might be null starts, for errors
+func tokenOf(exp ast.Expression) token.Token {
+	switch exp := exp.(type) {
+	case *ast.InfixExpression:
+		return tokenOf(exp.Left)
+	case *ast.MemberExpression:
+		return tokenOf(exp.Object)
+	case *ast.CallExpression:
+		return tokenOf(exp.Function)
+	case *ast.Identifier:
+		return exp.Token
+	case *ast.Null:
+		return exp.Token
+	}
+	return token.Token{}
+}
@@ -0,0 +1,299 @@
+package optimizer
+
+import (
+	"fmt"
+	"limLang/ast"
+	"limLang/token"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Optimizer walks a parsed program before it is evaluated, folding constant
+// expressions and dropping if/else-if branches whose condition is known at
+// compile time. Problems it can prove at compile time (like dividing by a
+// constant zero or using a nullable variable without checking it) are
+// collected in errors instead of being folded.
+type Optimizer struct {
+	errors []string
+}
+
+func New() *Optimizer {
+	return &Optimizer{errors: []string{}}
+}
+
+func (o *Optimizer) Errors() []string {
+	return o.errors
+}
+
+func (o *Optimizer) Optimize(program *ast.Program) *ast.Program {
+	if program == nil {
+		return nil
+	}
+	o.checkNulls(program)
+	program.Statements = o.optimizeStatements(program.Statements)
+	return program
+}
+
+func (o *Optimizer) optimizeStatements(stmts []ast.Statement) []ast.Statement {
+	result := []ast.Statement{}
+	for _, stmt := range stmts {
+		stmt = o.optimizeStatement(stmt)
+		if stmt != nil {
+			result = append(result, stmt)
+		}
+	}
+	return result
+}
+
+func (o *Optimizer) optimizeStatement(stmt ast.Statement) ast.Statement {
+	switch stmt := stmt.(type) {
+	case *ast.ExpressionStatement:
+		stmt.Expression = o.optimizeExpression(stmt.Expression)
+	case *ast.IntStatement:
+		stmt.Value = o.optimizeExpression(stmt.Value)
+	case *ast.FloatStatement:
+		stmt.Value = o.optimizeExpression(stmt.Value)
+	case *ast.BoolStatement:
+		stmt.Value = o.optimizeExpression(stmt.Value)
+	case *ast.StringStatement:
+		stmt.Value = o.optimizeExpression(stmt.Value)
+	case *ast.ReassignStatement:
+		stmt.Value = o.optimizeExpression(stmt.Value)
+	case *ast.MemberAssignStatement:
+		stmt.Value = o.optimizeExpression(stmt.Value)
+	case *ast.ReturnStatement:
+		stmt.ReturnValue = o.optimizeExpression(stmt.ReturnValue)
+	case *ast.ArrayLiteral:
+		stmt.Elements = o.optimizeExpressions(stmt.Elements)
+		if stmt.Value != nil {
+			stmt.Value = o.optimizeExpression(stmt.Value)
+		}
+	case *ast.IndexExpression:
+		stmt.Index = o.optimizeExpression(stmt.Index)
+	case *ast.BlockStatement:
+		o.optimizeBlock(stmt)
+	case *ast.FunctionStatement:
+		o.optimizeBlock(stmt.Body)
+	case *ast.IfStatement:
+		return o.optimizeIfStatement(stmt)
+	}
+	return stmt
+}
+
+func (o *Optimizer) optimizeBlock(block *ast.BlockStatement) {
+	if block == nil {
+		return
+	}
+	block.Statements = o.optimizeStatements(block.Statements)
+}
+
+// optimizeIfStatement rebuilds the if/else-if chain keeping only the cases
+// that can still run. A case whose condition folds to false is dropped, and a
+// case whose condition folds to true becomes the final (else) case since
+// nothing after it is reachable. Only the cases that are kept are optimized,
+// so code that can never run (like `if false { 1 / 0 }`) isn't reported.
+func (o *Optimizer) optimizeIfStatement(root *ast.IfStatement) ast.Statement {
+	var head, tail *ast.IfStatement
+	var last *ast.IfStatement
+
+	for cNode := root; cNode != nil; cNode = cNode.NextCase {
+		last = cNode
+		next := cNode.NextCase
+		cNode.NextCase = nil
+
+		if cNode.Condition != nil {
+			cNode.Condition = o.optimizeExpression(cNode.Condition)
+			if b, ok := cNode.Condition.(*ast.Boolean); ok {
+				if !b.Value {
+					cNode.NextCase = next
+					continue
+				}
+				cNode.Condition = nil
+				next = nil
+			}
+		}
+
+		o.optimizeBlock(cNode.Consequence)
+		if head == nil {
+			head = cNode
+		} else {
+			tail.NextCase = cNode
+		}
+		tail = cNode
+		cNode.NextCase = next
+		if next == nil {
+			break
+		}
+	}
+
+	if head == nil {
+		// every case was statically false, keep the last one so the
+		// statement still evaluates to null but drop its body
+		last.NextCase = nil
+		last.Consequence = &ast.BlockStatement{Token: last.Consequence.Token, Statements: []ast.Statement{}}
+		return last
+	}
+	tail.NextCase = nil
+	return head
+}
+
+func (o *Optimizer) optimizeExpressions(exps []ast.Expression) []ast.Expression {
+	for i, exp := range exps {
+		exps[i] = o.optimizeExpression(exp)
+	}
+	return exps
+}
+
+func (o *Optimizer) optimizeExpression(exp ast.Expression) ast.Expression {
+	switch exp := exp.(type) {
+	case *ast.PrefixExpression:
+		exp.Right = o.optimizeExpression(exp.Right)
+		if folded := o.foldPrefixExpression(exp); folded != nil {
+			return folded
+		}
+	case *ast.InfixExpression:
+		exp.Left = o.optimizeExpression(exp.Left)
+		exp.Right = o.optimizeExpression(exp.Right)
+		if folded := o.foldInfixExpression(exp); folded != nil {
+			return folded
+		}
+	case *ast.CallExpression:
+		exp.Function = o.optimizeExpression(exp.Function)
+		exp.Arguments = o.optimizeExpressions(exp.Arguments)
+	case *ast.IndexExpression:
+		exp.Index = o.optimizeExpression(exp.Index)
+	case *ast.MemberExpression:
+		exp.Object = o.optimizeExpression(exp.Object)
+	case *ast.InterpolatedString:
+		exp.Parts = o.optimizeExpressions(exp.Parts)
+		if folded := foldInterpolatedString(exp); folded != nil {
+			return folded
+		}
+	}
+	return exp
+}
+
+// foldInterpolatedString turns a string whose expressions are all string or
+// integer constants into a plain string
+func foldInterpolatedString(is *ast.InterpolatedString) ast.Expression {
+	var value strings.Builder
+	for _, part := range is.Parts {
+		switch part := part.(type) {
+		case *ast.StringVal:
+			value.WriteString(part.Value)
+		case *ast.IntegerLiteral:
+			value.WriteString(strconv.FormatInt(part.Value, 10))
+		default:
+			return nil
+		}
+	}
+	return &ast.StringVal{Token: token.Token{Type: token.STRING, Literal: value.String(), Line: is.Token.Line, Column: is.Token.Column}, Value: value.String()}
+}
+
+func (o *Optimizer) foldPrefixExpression(pe *ast.PrefixExpression) ast.Expression {
+	switch pe.Operator {
+	case "!":
+		switch right := pe.Right.(type) {
+		case *ast.Boolean:
+			return newBoolean(!right.Value)
+		case *ast.IntegerLiteral, *ast.StringVal:
+			// everything that isn't false or null is truthy
+			return newBoolean(false)
+		}
+	case "-":
+		if right, ok := pe.Right.(*ast.IntegerLiteral); ok && right.Value != math.MinInt64 {
+			return newInteger(-right.Value)
+		}
+	}
+	return nil
+}
+
+func (o *Optimizer) foldInfixExpression(ie *ast.InfixExpression) ast.Expression {
+	switch left := ie.Left.(type) {
+	case *ast.IntegerLiteral:
+		if right, ok := ie.Right.(*ast.IntegerLiteral); ok {
+			return o.foldIntegerInfixExpression(ie, left.Value, right.Value)
+		}
+	case *ast.Boolean:
+		if right, ok := ie.Right.(*ast.Boolean); ok {
+			switch ie.Operator {
+			case "==":
+				return newBoolean(left.Value == right.Value)
+			case "!=":
+				return newBoolean(left.Value != right.Value)
+			}
+		}
+	case *ast.StringVal:
+		// only concatenation is folded, comparisons are left to the
+		// evaluator so the folded program behaves exactly like the original
+		if right, ok := ie.Right.(*ast.StringVal); ok && ie.Operator == "+" {
+			return &ast.StringVal{Token: token.Token{Type: token.STRING, Literal: left.Value + right.Value}, Value: left.Value + right.Value}
+		}
+	}
+	return nil
+}
+
+// foldIntegerInfixExpression leaves operations that overflow alone, whether
+// that is an error depends on the interpreter running the program.
+func (o *Optimizer) foldIntegerInfixExpression(ie *ast.InfixExpression, leftVal, rightVal int64) ast.Expression {
+	switch ie.Operator {
+	case "+":
+		result := leftVal + rightVal
+		if (leftVal^result)&(rightVal^result) < 0 {
+			return nil
+		}
+		return newInteger(result)
+	case "-":
+		result := leftVal - rightVal
+		if (leftVal^rightVal)&(leftVal^result) < 0 {
+			return nil
+		}
+		return newInteger(result)
+	case "*":
+		result := leftVal * rightVal
+		if leftVal != 0 && (result/leftVal != rightVal || (leftVal == -1 && rightVal == math.MinInt64)) {
+			return nil
+		}
+		return newInteger(result)
+	case "/":
+		if rightVal == 0 {
+			o.errors = append(o.errors, fmt.Sprintf("line %d, column %d: division by zero: %s", ie.Token.Line, ie.Token.Column, ie.String()))
+			return nil
+		}
+		if leftVal == math.MinInt64 && rightVal == -1 {
+			return nil
+		}
+		return newInteger(leftVal / rightVal)
+	case "%":
+		if rightVal == 0 {
+			o.errors = append(o.errors, fmt.Sprintf("line %d, column %d: modulo by zero: %s", ie.Token.Line, ie.Token.Column, ie.String()))
+			return nil
+		}
+		return newInteger(leftVal % rightVal)
+	case "<":
+		return newBoolean(leftVal < rightVal)
+	case ">":
+		return newBoolean(leftVal > rightVal)
+	case "<=":
+		return newBoolean(leftVal <= rightVal)
+	case ">=":
+		return newBoolean(leftVal >= rightVal)
+	case "==":
+		return newBoolean(leftVal == rightVal)
+	case "!=":
+		return newBoolean(leftVal != rightVal)
+	}
+	return nil
+}
+
+func newInteger(value int64) *ast.IntegerLiteral {
+	return &ast.IntegerLiteral{Token: token.Token{Type: token.INT, Literal: strconv.FormatInt(value, 10)}, Value: value}
+}
+
+func newBoolean(value bool) *ast.Boolean {
+	if value {
+		return &ast.Boolean{Token: token.Token{Type: token.TRUE, Literal: "true"}, Value: true}
+	}
+	return &ast.Boolean{Token: token.Token{Type: token.FALSE, Literal: "false"}, Value: false}
+}
@@ -0,0 +1,216 @@
+package optimizer
+
+import (
+	"limLang/ast"
+	"limLang/evaluator"
+	"limLang/lexer"
+	"limLang/object"
+	"limLang/parser"
+	"testing"
+)
+
+func parse(input string) *ast.Program {
+	l := lexer.New(input)
+	p := parser.New(l)
+	return p.ParseProgram()
+}
+
+func TestConstantFolding(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"int dataExp = 2 * 4 + 123", "int dataExp = 131"},
+		{"5 + 2 * 10", "25"},
+		{"-(4 + 4)", "-8"},
+		{"10 / 3", "3"},
+		{"10 % 3", "1"},
+		{"9223372036854775807 + 1", "(9223372036854775807 + 1)"},
+		{"1 < 2", "true"},
+		{"2 <= 2", "true"},
+		{"1 >= 2", "false"},
+		{"(1 > 2) == false", "true"},
+		{"!true", "false"},
+		{"!!5", "true"},
+		{`"Hello" + "World"`, "HelloWorld"},
+		{"a + 2 * 3", "( a + 6)"},
+		{"-true", "(-true)"},
+		{`"n = ${2 * 21}!"`, "n = 42!"},
+		{`"n = ${n}"`, `"n = ${ n}"`},
+		{"(1 + 2)(4 * 2)", "3(8)"},
+		{`("a" + "b").len`, "ab.len"},
+	}
+
+	for _, tt := range tests {
+		o := New()
+		program := o.Optimize(parse(tt.input))
+		if len(o.Errors()) != 0 {
+			t.Fatalf("unexpected errors for %q: %v", tt.input, o.Errors())
+		}
+		if program.String() != tt.expected {
+			t.Errorf("wrong folding for %q. expected=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+}
+
+func TestDivisionByZero(t *testing.T) {
+	o := New()
+	program := o.Optimize(parse("int a = 10 / (5 - 5)"))
+	if len(o.Errors()) != 1 {
+		t.Fatalf("expected 1 error. got=%d %v", len(o.Errors()), o.Errors())
+	}
+	if o.Errors()[0] != "line 1, column 12: division by zero: (10 / 0)" {
+		t.Errorf("wrong error message. got=%q", o.Errors()[0])
+	}
+	if program.String() != "int a = (10 / 0)" {
+		t.Errorf("division by zero should not be folded. got=%q", program.String())
+	}
+}
+
+func TestDeadBranchElimination(t *testing.T) {
+	input := `if 1 > 2 {
+		10
+	} else if 2 > 1 {
+		20
+	} else if a {
+		30
+	} else {
+		40
+	}`
+	program := New().Optimize(parse(input))
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+	stmt, ok := program.Statements[0].(*ast.IfStatement)
+	if !ok {
+		t.Fatalf("stmt is not *ast.IfStatement. got=%T", program.Statements[0])
+	}
+	if stmt.Condition != nil {
+		t.Errorf("condition should have been removed. got=%s", stmt.Condition)
+	}
+	if stmt.NextCase != nil {
+		t.Errorf("unreachable cases should have been removed")
+	}
+	if stmt.Consequence.String() != "20\n" {
+		t.Errorf("wrong branch kept. got=%q", stmt.Consequence.String())
+	}
+}
+
+func TestOptimizedProgramOutput(t *testing.T) {
+	tests := []string{
+		"int dataExp = 2 * 4 + 123; dataExp",
+		"(5 + 10 * 2 + 15 / 3) * 2 + -10",
+		"if false { 10 }",
+		"if 1 > 2 { 10 } else { 20 }",
+		"if false { 10 } else if true { 30 } else { 20 }",
+		"if false { 10 } else if false { 30 } else { 20 }",
+		"int a = 3; if a > 2 { 1 } else if true { 2 } else { 3 }",
+		`string a = "Hello" + "World"; a`,
+		"bool a = !(1 < 2) == false; a",
+		"fn add (int x, int y) { x + y * (2 - 1) } add(5 + 5, add(5, 5));",
+		`fn sign(int x) {
+			if 1 > 2 {
+				return 0
+			} else if x < 0 {
+				return -1
+			}
+			return 1
+		}
+		sign(0 - 3)`,
+		"5 + true;",
+		"17 % 5 * 2",
+	}
+
+	for _, input := range tests {
+		expected := evaluator.Eval(parse(input), object.NewEnvironment())
+		optimized := evaluator.Eval(New().Optimize(parse(input)), object.NewEnvironment())
+		if expected.Inspect() != optimized.Inspect() {
+			t.Errorf("optimized program output differs for %q. expected=%q, got=%q",
+				input, expected.Inspect(), optimized.Inspect())
+		}
+	}
+}
+
+func TestDeadBranchesAreNotChecked(t *testing.T) {
+	for _, input := range []string{
+		"if false { 1 / 0 }",
+		"if 1 > 2 { 1 / 0 } else { 2 }",
+		"if true { 1 } else { 1 % 0 }",
+		"if true { 1 } else if a { 1 / 0 }",
+	} {
+		o := New()
+		o.Optimize(parse(input))
+		if len(o.Errors()) != 0 {
+			t.Errorf("%q: unexpected errors: %v", input, o.Errors())
+		}
+	}
+
+	o := New()
+	o.Optimize(parse("if a { 1 / 0 }"))
+	if len(o.Errors()) != 1 {
+		t.Errorf("expected the branch that can run to be checked. got=%v", o.Errors())
+	}
+}
+
+func TestModuloByZero(t *testing.T) {
+	o := New()
+	o.Optimize(parse("7 % 0"))
+	if len(o.Errors()) != 1 || o.Errors()[0] != "line 1, column 3: modulo by zero: (7 % 0)" {
+		t.Errorf("expected modulo by zero error. got=%v", o.Errors())
+	}
+}
+
+func TestNullChecks(t *testing.T) {
+	allowed := []string{
+		"int? a = null; a == null",
+		"int? a; int b = a ?? 1",
+		"string? s; s?.len",
+		"int? a; if a != null { a + 1 }",
+		"int? a; if a == null { 0 } else { a + 1 }",
+		"int? a; if a == null { 0 } else if a > 1 { a }",
+		"fn f(int? a) int { if a == null { return 0 }; return a + 1 }",
+		"fn f(int? a) int? { return a }",
+		"fn f(int? a) int { return 1 }; int? b; f(b)",
+		"int? a = 5; a + 1",
+		"int? a; a = 2; a * 3",
+		"int? a; int? b = a; b = a",
+		"fn f() int? { return null }; int? b = f()",
+		"int a = 1; if a > 0 { int? y = 1; y + 1 }",
+		"int? y = 1\nfn f() { int? y = null }\nif y != null { f(); y + 1 }",
+		`int? a; println("a", 1, null, a)`,
+	}
+	for _, input := range allowed {
+		o := New()
+		o.Optimize(parse(input))
+		if len(o.Errors()) != 0 {
+			t.Errorf("%q: unexpected errors: %v", input, o.Errors())
+		}
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"int? a; a + 1", "line 1, column 9: `a` might be null, check it with `if a != null` or give a default with `a ?? value`"},
+		{"int? a; int b = a", "line 1, column 17: `a` might be null, check it with `if a != null` or give a default with `a ?? value`"},
+		{"int b = null", "line 1, column 9: cannot use null as int, declare `b` as int? to allow it"},
+		{"int b = 1; b = null", "line 1, column 16: cannot use null as int, declare `b` as int? to allow it"},
+		{"int? a; if a == null { a + 1 }", "line 1, column 24: `a` might be null, check it with `if a != null` or give a default with `a ?? value`"},
+		{"int? a = 1; a = null; a + 1", "line 1, column 23: `a` might be null, check it with `if a != null` or give a default with `a ?? value`"},
+		{"int? a; if a != null { a = null; a + 1 }", "line 1, column 34: `a` might be null, check it with `if a != null` or give a default with `a ?? value`"},
+		{"int? a = 1; fn f() int { return a }", "line 1, column 33: `a` might be null, check it with `if a != null` or give a default with `a ?? value`"},
+		{"fn f(int a) int { return a }; int? b; f(b)", "line 1, column 41: `b` might be null, check it with `if b != null` or give a default with `b ?? value`"},
+		{"fn f() int? { return null }; f() + 1", "line 1, column 30: `f()` might be null, check it first or give a default with `?? value`"},
+		{"string? s; s?.len + 1", "line 1, column 12: `s?.len` might be null, check it first or give a default with `?? value`"},
+		{`string? s; "${s}"`, "line 1, column 15: `s` might be null, check it with `if s != null` or give a default with `s ?? value`"},
+		{"int? y = 1\nfn f() { y = null }\nif y != null { f(); y + 1 }", "line 3, column 21: `y` might be null, check it with `if y != null` or give a default with `y ?? value`"},
+		{"int a = 1\nif a > 0 { int? y = null }\ny + 1", "line 3, column 1: `y` might be null, check it with `if y != null` or give a default with `y ?? value`"},
+	}
+	for _, tt := range tests {
+		o := New()
+		o.Optimize(parse(tt.input))
+		if len(o.Errors()) != 1 || o.Errors()[0] != tt.expected {
+			t.Errorf("%q: wrong errors. expected=%q, got=%q", tt.input, tt.expected, o.Errors())
+		}
+	}
+}
@@ -1,34 +1,45 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 	"limLang/ast"
 	"limLang/lexer"
 	"limLang/token"
+	"math"
 	"strconv"
+	"strings"
 )
 
 const (
 	_ int = iota
 	LOWEST
+	DEFAULT     // a ?? b
 	EQUALS      // ==
-	LESSGREATER // > or <
+	LESSGREATER // > or <, >= or <=
 	SUM         // +
 	PRODUCT     // *
 	PREFIX      // -X or !X
 	CALL        // myFunction(X)
+	MEMBER      // obj.field
 )
 
 var precedences = map[token.TokenType]int{
-	token.EQ:       EQUALS,
-	token.NOT_EQ:   EQUALS,
-	token.LT:       LESSGREATER,
-	token.GT:       LESSGREATER,
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.SLASH:    PRODUCT,
-	token.ASTERISK: PRODUCT,
-	token.LPAREN:   CALL,
+	token.NULLISH:      DEFAULT,
+	token.EQ:           EQUALS,
+	token.NOT_EQ:       EQUALS,
+	token.LT:           LESSGREATER,
+	token.GT:           LESSGREATER,
+	token.LTEQ:         LESSGREATER,
+	token.GTEQ:         LESSGREATER,
+	token.PLUS:         SUM,
+	token.MINUS:        SUM,
+	token.SLASH:        PRODUCT,
+	token.ASTERISK:     PRODUCT,
+	token.MODULUS:      PRODUCT,
+	token.LPAREN:       CALL,
+	token.PERIOD:       MEMBER,
+	token.OPTIONAL_DOT: MEMBER,
 }
 
 type (
@@ -43,9 +54,16 @@ type Parser struct {
 	curToken  token.Token
 	peekToken token.Token
 
-	curLineNum int
+	// doc comments of curToken and peekToken, see readToken
+	curDoc  string
+	peekDoc string
 
-	err struct{}
+	// for telling which newlines end statements, see readToken: the last
+	// token that wasn't a comment or newline and the ( [ ${ and { we are in
+	lastCode token.TokenType
+	brackets []token.TokenType
+
+	errors []string
 
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
@@ -57,14 +75,12 @@ func (p *Parser) ParseProgram() *ast.Program {
 
 	for p.curToken.Type != token.EOF {
 		if p.curToken.Type == token.ILLEGAL {
-			// handle the illegal token
-			return nil
-		}
-		if p.curToken.Type == token.ENDOFLINE {
-			p.curLineNum += 1
+			// the error was recorded when the lexer gave us the token,
+			// nothing after it can be trusted
+			return program
 		}
 
-		stmt := p.parseStatement()
+		stmt := p.parseTerminatedStatement()
 		if stmt != nil {
 			program.Statements = append(program.Statements, stmt)
 		}
@@ -75,18 +91,22 @@ func (p *Parser) ParseProgram() *ast.Program {
 
 func New(l *lexer.Lexer) *Parser {
 	p := &Parser{
-		l: l,
+		l:      l,
+		errors: []string{},
 	}
 
 	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
 	p.prefixParseFns[token.IDENT] = p.parseIdentifier
 	p.prefixParseFns[token.INT] = p.parseIntegerLiteral
+	p.prefixParseFns[token.FLOAT] = p.parseFloatLiteral
 	p.prefixParseFns[token.BANG] = p.parsePrefixExpression
 	p.prefixParseFns[token.MINUS] = p.parsePrefixExpression
 	p.prefixParseFns[token.TRUE] = p.parseBoolean
 	p.prefixParseFns[token.FALSE] = p.parseBoolean
+	p.prefixParseFns[token.NULL] = p.parseNull
 	p.prefixParseFns[token.LPAREN] = p.parseGroupedExpression
 	p.prefixParseFns[token.STRING] = p.parseStringLiteral
+	p.prefixParseFns[token.TEMPLATE_HEAD] = p.parseInterpolatedString
 	// p.prefixParseFns[token.LBRACK] = p.parseArrayLiteral
 
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
@@ -94,11 +114,17 @@ func New(l *lexer.Lexer) *Parser {
 	p.infixParseFns[token.MINUS] = p.parseInfixExpression
 	p.infixParseFns[token.SLASH] = p.parseInfixExpression
 	p.infixParseFns[token.ASTERISK] = p.parseInfixExpression
+	p.infixParseFns[token.MODULUS] = p.parseInfixExpression
 	p.infixParseFns[token.EQ] = p.parseInfixExpression
 	p.infixParseFns[token.NOT_EQ] = p.parseInfixExpression
 	p.infixParseFns[token.LT] = p.parseInfixExpression
 	p.infixParseFns[token.GT] = p.parseInfixExpression
+	p.infixParseFns[token.LTEQ] = p.parseInfixExpression
+	p.infixParseFns[token.GTEQ] = p.parseInfixExpression
 	p.infixParseFns[token.LPAREN] = p.parseCallExpression
+	p.infixParseFns[token.PERIOD] = p.parseMemberExpression
+	p.infixParseFns[token.OPTIONAL_DOT] = p.parseMemberExpression
+	p.infixParseFns[token.NULLISH] = p.parseInfixExpression
 
 	// Read two tokens, so curToken and peekToken are both set
 	p.nextToken()
@@ -107,31 +133,197 @@ func New(l *lexer.Lexer) *Parser {
 }
 
 func (p *Parser) nextToken() {
-	p.curToken = p.peekToken
-	p.peekToken = p.l.NextToken()
-	if p.curToken.Type == token.ENDOFLINE {
-		p.curLineNum += 1
-		p.nextToken()
+	p.curToken, p.curDoc = p.peekToken, p.peekDoc
+	p.peekToken = p.readToken()
+	if p.peekToken.Type == token.ILLEGAL {
+		// the literal of an illegal token says what is wrong with it
+		p.errorf(p.peekToken, "%s", p.peekToken.Literal)
 	}
 }
 
-func (p *Parser) parseStatement() ast.Statement {
-	// right now we are skipping all the \n tokens
-	for p.curToken.Type == token.ENDOFLINE {
+// readToken gets the next token from the lexer.
+//
+// Statements end with a ; or a newline. A newline only ends a statement if
+// the line ends in something a statement can end with, see endsStatement,
+// and it isn't inside ( ), [ ] or ${ }. The other newlines are dropped
+// here, so
+//
+//	int a = 1 +
+//		2
+//	f(a,
+//		b)
+//
+// are one statement each, while
+//
+//	a
+//	-b
+//
+// are two.
+//
+// When the lexer keeps comments, the ones on the lines right above a token
+// become its doc comment in peekDoc, see docComments.
+func (p *Parser) readToken() token.Token {
+	for {
+		tok := p.l.NextToken()
+		switch tok.Type {
+		case token.ENDOFLINE:
+			if !endsStatement[p.lastCode] || p.inBrackets() {
+				continue
+			}
+			p.lastCode = tok.Type
+			p.peekDoc = ""
+			return tok
+		}
+		p.trackBrackets(tok.Type)
+		p.lastCode = tok.Type
+		p.peekDoc = docText(docComments(tok))
+		return tok
+	}
+}
+
+// endsStatement is what a line has to end with for the newline after it
+// to end the statement
+var endsStatement = map[token.TokenType]bool{
+	token.IDENT:         true,
+	token.INT:           true,
+	token.FLOAT:         true,
+	token.STRING:        true,
+	token.TEMPLATE_TAIL: true,
+	token.TRUE:          true,
+	token.FALSE:         true,
+	token.NULL:          true,
+	token.RETURN:        true,
+	token.RPAREN:        true,
+	token.RBRACK:        true,
+	token.RBRACE:        true,
+}
+
+func (p *Parser) trackBrackets(t token.TokenType) {
+	switch t {
+	case token.LPAREN, token.LBRACK, token.TEMPLATE_HEAD, token.LBRACE:
+		p.brackets = append(p.brackets, t)
+	case token.RPAREN, token.RBRACK, token.TEMPLATE_TAIL, token.RBRACE:
+		if len(p.brackets) > 0 {
+			p.brackets = p.brackets[:len(p.brackets)-1]
+		}
+	}
+}
+
+// inBrackets tells if we are inside ( ), [ ] or ${ }, a { } in them makes
+// newlines count again
+func (p *Parser) inBrackets() bool {
+	return len(p.brackets) > 0 && p.brackets[len(p.brackets)-1] != token.LBRACE
+}
+
+// docComments are the leading comments of tok that end on the line right
+// before it with no blank lines between them
+func docComments(tok token.Token) []token.Token {
+	if tok.Trivia == nil {
+		return nil
+	}
+	comments := tok.Trivia.Leading
+	line := tok.Line
+	start := len(comments)
+	for start > 0 {
+		comment := comments[start-1]
+		if comment.Line+strings.Count(comment.Literal, "\n") != line-1 {
+			break
+		}
+		start--
+		line = comment.Line
+	}
+	return comments[start:]
+}
+
+// docText is the text of doc comments without the comment markers
+func docText(comments []token.Token) string {
+	lines := []string{}
+	for _, comment := range comments {
+		text := comment.Literal
+		if strings.HasPrefix(text, "//") {
+			lines = append(lines, strings.TrimPrefix(text[2:], " "))
+			continue
+		}
+		text = strings.TrimSuffix(strings.TrimPrefix(text, "/*"), "*/")
+		for _, line := range strings.Split(strings.Trim(text, "\n"), "\n") {
+			lines = append(lines, strings.TrimSpace(line))
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// parseTerminatedStatement parses a statement and the ; or newline after
+// it, empty statements like the second ; in `int a = 1;;` give nil
+func (p *Parser) parseTerminatedStatement() ast.Statement {
+	if p.curTokenIs(token.SEMICOLON) || p.curTokenIs(token.ENDOFLINE) {
+		return nil
+	}
+	errCount := len(p.errors)
+	stmt := p.parseStatement()
+	p.endStatement(len(p.errors) > errCount)
+	return stmt
+}
+
+// endStatement moves onto the ; or newline that ends the statement we just
+// parsed, a statement can also end right before a } or the end of the
+// input, and one that ends with a block, like a function, needs nothing
+// after it. Anything else is an error, unless the statement already had
+// one, and is skipped up to the end of the statement.
+func (p *Parser) endStatement(failed bool) {
+	if p.peekEndsStatement() {
+		if p.peekTokenIs(token.SEMICOLON) || p.peekTokenIs(token.ENDOFLINE) {
+			p.nextToken()
+		}
+		return
+	}
+	if p.curTokenIs(token.RBRACE) && !failed {
+		return
+	}
+	if !failed {
+		p.errorf(p.peekToken, "expected ; or a new line after the statement, got %q", p.peekToken.Literal)
+	}
+	for !p.peekEndsStatement() && !p.peekTokenIs(token.ILLEGAL) {
+		p.nextToken()
+	}
+	if p.peekTokenIs(token.SEMICOLON) || p.peekTokenIs(token.ENDOFLINE) {
 		p.nextToken()
 	}
+}
+
+func (p *Parser) peekEndsStatement() bool {
+	switch p.peekToken.Type {
+	case token.SEMICOLON, token.ENDOFLINE, token.RBRACE, token.EOF:
+		return true
+	}
+	return false
+}
+
+func (p *Parser) parseStatement() ast.Statement {
 	switch p.curToken.Type {
 	case token.Keyword_INT:
 		if p.peekToken.Type == token.LBRACK {
 			return p.parseArrayLiteral()
 		}
 		return p.parseIntStatement()
+	case token.Keyword_FLOAT:
+		if p.peekToken.Type == token.LBRACK {
+			return p.parseArrayLiteral()
+		}
+		return p.parseFloatStatement()
 	case token.Keyword_BOOL:
+		if p.peekToken.Type == token.LBRACK {
+			return p.parseArrayLiteral()
+		}
 		return p.parseBoolStatement()
 	case token.Keyword_STRING:
+		if p.peekToken.Type == token.LBRACK {
+			return p.parseArrayLiteral()
+		}
 		return p.parseStringStatement()
 	case token.FUNCTION:
 		return p.parseFunctionStatement()
+	case token.PUB, token.CONST:
+		return p.parseModifiedStatement()
 	case token.IF:
 		return p.parseIfStatement()
 	case token.RETURN:
@@ -141,8 +333,8 @@ func (p *Parser) parseStatement() ast.Statement {
 			return p.parseDefineStatement()
 		} else if p.peekToken.Type == token.LBRACK {
 			return p.parseIndexExpression()
-			// } else if p.peekToken.Type == token.ASSIGN {
-			// 	return p.parseReassignStatement()
+		} else if p.peekToken.Type == token.ASSIGN {
+			return p.parseReassignStatement()
 		}
 		fallthrough
 	default:
@@ -150,6 +342,75 @@ func (p *Parser) parseStatement() ast.Statement {
 	}
 }
 
+// parseModifiedStatement parses declarations starting with pub, const or
+// both, like `pub const int limit = 10`. pub works on functions and typed
+// variables, const only on typed variables and they need a value.
+func (p *Parser) parseModifiedStatement() ast.Statement {
+	doc := p.curDoc
+	pub := p.curToken.Type == token.PUB
+	if pub {
+		p.nextToken()
+	}
+	constant := p.curToken.Type == token.CONST
+	if constant {
+		p.nextToken()
+	}
+
+	switch p.curToken.Type {
+	case token.FUNCTION:
+		if constant {
+			break
+		}
+		stmt := p.parseFunctionStatement()
+		if stmt == nil {
+			return nil
+		}
+		stmt.Pub, stmt.Doc = pub, doc
+		return stmt
+	case token.Keyword_INT, token.Keyword_FLOAT, token.Keyword_BOOL, token.Keyword_STRING:
+		if p.peekToken.Type == token.LBRACK {
+			break
+		}
+		var stmt ast.Statement
+		var name *ast.Identifier
+		switch p.curToken.Type {
+		case token.Keyword_INT:
+			if s := p.parseIntStatement(); s != nil {
+				s.Pub, s.Const, s.Doc = pub, constant, doc
+				stmt, name = s, s.Name
+			}
+		case token.Keyword_FLOAT:
+			if s := p.parseFloatStatement(); s != nil {
+				s.Pub, s.Const, s.Doc = pub, constant, doc
+				stmt, name = s, s.Name
+			}
+		case token.Keyword_BOOL:
+			if s := p.parseBoolStatement(); s != nil {
+				s.Pub, s.Const, s.Doc = pub, constant, doc
+				stmt, name = s, s.Name
+			}
+		case token.Keyword_STRING:
+			if s := p.parseStringStatement(); s != nil {
+				s.Pub, s.Const, s.Doc = pub, constant, doc
+				stmt, name = s, s.Name
+			}
+		}
+		// without a value the parser stops at the name
+		if constant && name != nil && p.curToken == name.Token {
+			p.errorf(name.Token, "constant %s needs a value", name.Value)
+			return nil
+		}
+		return stmt
+	}
+
+	if constant {
+		p.errorf(p.curToken, "const goes before a typed variable, like `const int x = 1`, got %q", p.curToken.Literal)
+	} else {
+		p.errorf(p.curToken, "pub goes before fn, const or a typed variable, got %q", p.curToken.Literal)
+	}
+	return nil
+}
+
 func (p *Parser) parseDefineStatement() ast.Statement {
 	ident := p.curToken
 	p.nextToken()
@@ -160,9 +421,13 @@ func (p *Parser) parseDefineStatement() ast.Statement {
 		stmt.Name = &ast.Identifier{Token: ident, Value: ident.Literal}
 
 		stmt.Value = p.parseExpression(LOWEST)
-		if p.peekTokenIs(token.SEMICOLON) {
-			p.nextToken()
-		}
+		return stmt
+
+	case token.FLOAT:
+		stmt := &ast.FloatStatement{Token: token.Token{Type: token.FLOAT, Literal: "float"}}
+		stmt.Name = &ast.Identifier{Token: ident, Value: ident.Literal}
+
+		stmt.Value = p.parseExpression(LOWEST)
 		return stmt
 
 	case token.STRING:
@@ -170,23 +435,28 @@ func (p *Parser) parseDefineStatement() ast.Statement {
 		stmt.Name = &ast.Identifier{Token: ident, Value: ident.Literal}
 
 		stmt.Value = &ast.StringVal{Token: token.Token{Type: token.STRING, Literal: "string"}, Value: p.curToken.Literal}
-		if p.peekTokenIs(token.SEMICOLON) {
-			p.nextToken()
-		}
 		return stmt
 	case token.TRUE, token.FALSE, token.BANG:
 		stmt := &ast.BoolStatement{Token: token.Token{Type: token.BOOL, Literal: "bool"}}
 		stmt.Name = &ast.Identifier{Token: ident, Value: ident.Literal}
 
 		stmt.Value = p.parseExpression(LOWEST)
-		if p.peekTokenIs(token.SEMICOLON) {
-			p.nextToken()
-		}
 		return stmt
 	}
+	p.errorf(p.curToken, "can't tell the type of %s from %q, declare it with a type instead of :=", ident.Literal, p.curToken.Literal)
 	return nil
 }
 
+func (p *Parser) parseReassignStatement() *ast.ReassignStatement {
+	name := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	p.nextToken()
+	stmt := &ast.ReassignStatement{Token: p.curToken, Name: name}
+	p.nextToken()
+
+	stmt.Value = p.parseExpression(LOWEST)
+	return stmt
+}
+
 func (p *Parser) parseIdentifier() ast.Expression {
 	return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 }
@@ -195,21 +465,98 @@ func (p *Parser) parseStringLiteral() ast.Expression {
 	return &ast.StringVal{Token: p.curToken, Value: p.curToken.Literal}
 }
 
+func (p *Parser) parseInterpolatedString() ast.Expression {
+	str := &ast.InterpolatedString{Token: p.curToken}
+	str.Parts = append(str.Parts, &ast.StringVal{Token: p.curToken, Value: p.curToken.Literal})
+	for {
+		if p.peekTokenIs(token.TEMPLATE_MIDDLE) || p.peekTokenIs(token.TEMPLATE_TAIL) {
+			p.errorf(p.peekToken, "empty ${} in string")
+			return nil
+		}
+		p.nextToken()
+		str.Parts = append(str.Parts, p.parseExpression(LOWEST))
+
+		if p.peekTokenIs(token.TEMPLATE_TAIL) {
+			p.nextToken()
+			str.Parts = append(str.Parts, &ast.StringVal{Token: p.curToken, Value: p.curToken.Literal})
+			return str
+		}
+		if !p.expectPeek(token.TEMPLATE_MIDDLE) {
+			return nil
+		}
+		str.Parts = append(str.Parts, &ast.StringVal{Token: p.curToken, Value: p.curToken.Literal})
+	}
+}
+
 func (p *Parser) parseIntegerLiteral() ast.Expression {
 	lit := &ast.IntegerLiteral{Token: p.curToken}
 
-	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
-	if err != nil {
-		// msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
-		// p.errors = append(p.errors, msg)
+	if _, base, _ := integerDigits(p.curToken.Literal); base == 10 && len(p.curToken.Literal) > 1 && p.curToken.Literal[0] == '0' {
+		// C would read this as octal, say so instead of guessing
+		p.errorf(p.curToken, "integer literal %s can't start with 0, use the 0o prefix for octal", p.curToken.Literal)
+		return nil
+	}
+	value, err := parseInteger(p.curToken.Literal)
+	if errors.Is(err, strconv.ErrRange) || value > math.MaxInt64 {
+		p.errorf(p.curToken, "integer literal %s is out of range", p.curToken.Literal)
+		return nil
+	} else if err != nil {
+		p.errorf(p.curToken, "invalid integer literal %s", p.curToken.Literal)
 		return nil
 	}
 
-	lit.Value = value
+	lit.Value = int64(value)
 
 	return lit
 }
 
+// parseInteger gives the value of an integer literal without its sign, see
+// integerDigits
+func parseInteger(lit string) (uint64, error) {
+	digits, base, ok := integerDigits(lit)
+	if !ok {
+		return 0, strconv.ErrSyntax
+	}
+	return strconv.ParseUint(digits, base, 64)
+}
+
+// integerDigits splits an integer literal into its digits and base, the
+// 0x, 0o and 0b prefixes pick the base and anything else is decimal. The _
+// separators are dropped, ok is false if one isn't between two digits (or
+// right after the prefix).
+func integerDigits(lit string) (digits string, base int, ok bool) {
+	base = 10
+	if len(lit) > 1 && lit[0] == '0' {
+		switch lit[1] {
+		case 'x', 'X':
+			base = 16
+		case 'o', 'O':
+			base = 8
+		case 'b', 'B':
+			base = 2
+		}
+	}
+	if base != 10 {
+		lit = lit[2:]
+		if strings.HasPrefix(lit, "_") {
+			lit = lit[1:]
+		}
+	}
+	if lit == "" || lit[0] == '_' || lit[len(lit)-1] == '_' || strings.Contains(lit, "__") {
+		return "", base, false
+	}
+	return strings.ReplaceAll(lit, "_", ""), base, true
+}
+
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
+	if err != nil {
+		p.errorf(p.curToken, "could not parse %q as float", p.curToken.Literal)
+		return nil
+	}
+	return &ast.FloatLiteral{Token: p.curToken, Value: value}
+}
+
 func (p *Parser) parseIndexExpression() *ast.IndexExpression {
 	exp := &ast.IndexExpression{Token: p.curToken, Ident: &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}}
 	p.nextToken()
@@ -247,6 +594,12 @@ func (p *Parser) parseArrayLiteral() *ast.ArrayLiteral {
 	}
 	p.nextToken()
 
+	// the array can also come from an expression, like `int []b = rest(a)`
+	if !p.curTokenIs(token.LBRACK) {
+		array.Value = p.parseExpression(LOWEST)
+		return array
+	}
+
 	// now our current token is [ paran
 	array.Elements = p.parseExpressionList(token.RBRACK)
 	return array
@@ -274,89 +627,142 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 }
 
 func (p *Parser) parseIntStatement() *ast.IntStatement {
-	stmt := &ast.IntStatement{Token: p.curToken}
+	stmt := &ast.IntStatement{Token: p.curToken, Doc: p.curDoc}
+	stmt.Nullable = p.skipNullableMark()
 	if !p.expectPeek(token.IDENT) {
 		return nil
 	}
 	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 	if !p.expectPeek(token.ASSIGN) {
-		if p.peekTokenIs(token.SEMICOLON) {
+		if p.peekEndsStatement() {
 			stmt.Value = &ast.IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "int"}, Value: 0}
+			if stmt.Nullable {
+				stmt.Value = p.nullWithoutValue()
+			}
 			return stmt
 		}
 		return nil
 	}
 	p.nextToken()
 	stmt.Value = p.parseExpression(LOWEST)
-	if p.peekTokenIs(token.SEMICOLON) {
-		p.nextToken()
+	return stmt
+}
+
+func (p *Parser) parseFloatStatement() *ast.FloatStatement {
+	stmt := &ast.FloatStatement{Token: p.curToken, Doc: p.curDoc}
+	stmt.Nullable = p.skipNullableMark()
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	if !p.expectPeek(token.ASSIGN) {
+		if p.peekEndsStatement() {
+			stmt.Value = &ast.FloatLiteral{Token: token.Token{Type: token.FLOAT, Literal: "float"}, Value: 0}
+			if stmt.Nullable {
+				stmt.Value = p.nullWithoutValue()
+			}
+			return stmt
+		}
+		return nil
 	}
+	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
 	return stmt
 }
 
 func (p *Parser) parseBoolStatement() *ast.BoolStatement {
-	stmt := &ast.BoolStatement{Token: p.curToken}
+	stmt := &ast.BoolStatement{Token: p.curToken, Doc: p.curDoc}
+	stmt.Nullable = p.skipNullableMark()
 	if !p.expectPeek(token.IDENT) {
 		return nil
 	}
 	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 	if !p.expectPeek(token.ASSIGN) {
-		if p.peekTokenIs(token.SEMICOLON) {
+		if p.peekEndsStatement() {
 			stmt.Value = &ast.Boolean{Token: token.Token{Type: token.BOOL, Literal: "bool"}, Value: false}
+			if stmt.Nullable {
+				stmt.Value = p.nullWithoutValue()
+			}
 			return stmt
 		}
 		return nil
 	}
 	p.nextToken()
 	stmt.Value = p.parseExpression(LOWEST)
-	if p.peekTokenIs(token.SEMICOLON) {
-		p.nextToken()
-	}
 	return stmt
 }
 
 func (p *Parser) parseStringStatement() *ast.StringStatement {
-	stmt := &ast.StringStatement{Token: p.curToken}
+	stmt := &ast.StringStatement{Token: p.curToken, Doc: p.curDoc}
+	stmt.Nullable = p.skipNullableMark()
 	if !p.expectPeek(token.IDENT) {
 		return nil
 	}
 	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 	if !p.expectPeek(token.ASSIGN) {
-		if p.peekTokenIs(token.SEMICOLON) {
+		if p.peekEndsStatement() {
 			stmt.Value = &ast.StringVal{Token: token.Token{Type: token.STRING, Literal: "string"}, Value: ""}
+			if stmt.Nullable {
+				stmt.Value = p.nullWithoutValue()
+			}
 			return stmt
 		}
 		return nil
 	}
 	p.nextToken()
 	stmt.Value = p.parseExpression(LOWEST)
-	if p.peekTokenIs(token.SEMICOLON) {
-		p.nextToken()
-	}
 	return stmt
 }
 
+// skipNullableMark skips the ? of a type like `int?` and tells if there
+// was one
+func (p *Parser) skipNullableMark() bool {
+	if !p.peekTokenIs(token.QUESTION) {
+		return false
+	}
+	p.nextToken()
+	return true
+}
+
+// nullWithoutValue is the value of a nullable variable declared without
+// one, `int? x` starts out as null
+func (p *Parser) nullWithoutValue() *ast.Null {
+	return &ast.Null{Token: token.Token{Type: token.NULL, Literal: "null", Line: p.curToken.Line, Column: p.curToken.Column}}
+}
+
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	rst := &ast.ReturnStatement{Token: p.curToken}
+	if p.peekEndsStatement() {
+		// a bare return
+		return rst
+	}
 
 	p.nextToken()
 
 	rst.ReturnValue = p.parseExpression(LOWEST)
 
-	if p.peekTokenIs(token.SEMICOLON) {
-		p.nextToken()
-	}
 	return rst
 }
-func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
+func (p *Parser) parseExpressionStatement() ast.Statement {
 	stmt := &ast.ExpressionStatement{Token: p.curToken}
 	stmt.Expression = p.parseExpression(LOWEST)
-	if p.peekTokenIs(token.SEMICOLON) {
-		p.nextToken()
+	if member, ok := stmt.Expression.(*ast.MemberExpression); ok && p.peekTokenIs(token.ASSIGN) {
+		return p.parseMemberAssignStatement(member)
 	}
 	return stmt
 }
 
+// parseMemberAssignStatement parses `obj.field = value`, target is what we
+// already parsed before the '='
+func (p *Parser) parseMemberAssignStatement(target *ast.MemberExpression) *ast.MemberAssignStatement {
+	p.nextToken()
+	stmt := &ast.MemberAssignStatement{Token: p.curToken, Target: target}
+	p.nextToken()
+
+	stmt.Value = p.parseExpression(LOWEST)
+	return stmt
+}
+
 func (p *Parser) parseExpression(precedence int) ast.Expression {
 	prefix := p.prefixParseFns[p.curToken.Type]
 	if prefix == nil {
@@ -383,6 +789,17 @@ func (p *Parser) parsePrefixExpression() ast.Expression {
 		Operator: p.curToken.Literal,
 	}
 
+	// -9223372036854775808 fits in an integer even though
+	// 9223372036854775808 doesn't
+	if expression.Operator == "-" && p.peekTokenIs(token.INT) {
+		if value, err := parseInteger(p.peekToken.Literal); err == nil && value == 1<<63 {
+			tok := p.curToken
+			p.nextToken()
+			tok.Type, tok.Literal = token.INT, "-"+p.curToken.Literal
+			return &ast.IntegerLiteral{Token: tok, Value: math.MinInt64}
+		}
+	}
+
 	p.nextToken()
 
 	expression.Right = p.parseExpression(PREFIX)
@@ -415,6 +832,10 @@ func (p *Parser) parseGroupedExpression() ast.Expression {
 
 	return exp
 }
+func (p *Parser) parseNull() ast.Expression {
+	return &ast.Null{Token: p.curToken}
+}
+
 func (p *Parser) parseBoolean() ast.Expression {
 	return &ast.Boolean{Token: p.curToken, Value: p.curTokenIs(token.TRUE)}
 }
@@ -473,7 +894,7 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	p.nextToken()
 
 	for !p.curTokenIs(token.RBRACE) && !p.curTokenIs(token.EOF) {
-		stmt := p.parseStatement()
+		stmt := p.parseTerminatedStatement()
 		if stmt != nil {
 			block.Statements = append(block.Statements, stmt)
 		}
@@ -483,7 +904,7 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 }
 
 func (p *Parser) parseFunctionStatement() *ast.FunctionStatement {
-	lit := &ast.FunctionStatement{Token: p.curToken}
+	lit := &ast.FunctionStatement{Token: p.curToken, Doc: p.curDoc}
 
 	if !p.expectPeek(token.IDENT) {
 		return nil
@@ -498,6 +919,9 @@ func (p *Parser) parseFunctionStatement() *ast.FunctionStatement {
 	if p.peekTokenIs(token.Keyword_INT) {
 		p.nextToken()
 		lit.ReturnType = p.curToken
+	} else if p.peekTokenIs(token.Keyword_FLOAT) {
+		p.nextToken()
+		lit.ReturnType = p.curToken
 	} else if p.peekTokenIs(token.Keyword_BOOL) {
 		p.nextToken()
 		lit.ReturnType = p.curToken
@@ -505,6 +929,9 @@ func (p *Parser) parseFunctionStatement() *ast.FunctionStatement {
 		p.nextToken()
 		lit.ReturnType = p.curToken
 	}
+	if lit.ReturnType.Type != "" {
+		lit.NullableReturn = p.skipNullableMark()
+	}
 
 	if !p.expectPeek(token.LBRACE) {
 		return nil
@@ -524,42 +951,49 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 	}
 
 	p.nextToken()
-
-	ident1 := &ast.Identifier{}
-	if p.curToken.Type == token.Keyword_INT || p.curToken.Type == token.Keyword_BOOL || p.curToken.Type == token.Keyword_STRING {
-		ident1.HoldsVarType = p.curToken
-		p.nextToken()
-	} else {
-		fmt.Println("there was error while parsing function paramerters!")
-		p.nextToken()
-	}
-	ident1.Token = p.curToken
-	ident1.Value = p.curToken.Literal
-	identifiers = append(identifiers, ident1)
+	identifiers = append(identifiers, p.parseFunctionParameter())
 
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
 		p.nextToken()
-
-		ident2 := &ast.Identifier{}
-		if p.curToken.Type == token.Keyword_INT || p.curToken.Type == token.Keyword_BOOL || p.curToken.Type == token.Keyword_STRING {
-			ident2.HoldsVarType = p.curToken
-			p.nextToken()
-		} else {
-			fmt.Println("there was error while parsing function paramerters!")
-			p.nextToken()
-		}
-		ident2.Token = p.curToken
-		ident2.Value = p.curToken.Literal
-		identifiers = append(identifiers, ident2)
+		identifiers = append(identifiers, p.parseFunctionParameter())
 	}
 
 	if !p.expectPeek(token.RPAREN) {
 		return nil
 	}
+
+	for _, ident := range identifiers[:len(identifiers)-1] {
+		if ident.Variadic {
+			p.errorf(ident.Token, "only the last function parameter can be variadic")
+			return nil
+		}
+	}
 	return identifiers
 }
 
+// parseFunctionParameter parses a single `int x`, `int? x` or `int ...xs`
+// parameter
+func (p *Parser) parseFunctionParameter() *ast.Identifier {
+	ident := &ast.Identifier{}
+	switch p.curToken.Type {
+	case token.Keyword_INT, token.Keyword_FLOAT, token.Keyword_BOOL, token.Keyword_STRING:
+		ident.HoldsVarType = p.curToken
+		ident.Nullable = p.skipNullableMark()
+		p.nextToken()
+	default:
+		p.errorf(p.curToken, "expected type of function parameter, got %s", p.curToken.Type)
+		p.nextToken()
+	}
+	if p.curTokenIs(token.ELLIPSIS) {
+		ident.Variadic = true
+		p.nextToken()
+	}
+	ident.Token = p.curToken
+	ident.Value = p.curToken.Literal
+	return ident
+}
+
 func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 	exp := &ast.CallExpression{Token: p.curToken, Function: function}
 	exp.Arguments = p.parseCallArguments()
@@ -567,6 +1001,16 @@ func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 	return exp
 }
 
+func (p *Parser) parseMemberExpression(object ast.Expression) ast.Expression {
+	exp := &ast.MemberExpression{Token: p.curToken, Object: object, Optional: p.curTokenIs(token.OPTIONAL_DOT)}
+	if !p.expectPeek(token.IDENT) {
+		p.errorf(p.peekToken, "expected field or method name after '%s', got %s", p.curToken.Literal, p.peekToken.Type)
+		return nil
+	}
+	exp.Property = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	return exp
+}
+
 func (p *Parser) parseCallArguments() []ast.Expression {
 	args := []ast.Expression{}
 
@@ -626,12 +1070,19 @@ func (p *Parser) curPrecedence() int {
 }
 
 func (p *Parser) noPrefixParseFnError(t token.TokenType) {
-	// msg := fmt.Sprintf("no prefix parse function for %s found", t)
-	// This error function is still incomplete and doesn't return a proper error
-	// fmt.Printf("no prefix parse function for %s found\n", t)
-	p.gotError(t)
+	if t == token.ILLEGAL {
+		// already reported
+		return
+	}
+	p.errorf(p.curToken, "no prefix parse function for %s found", t)
+}
+
+func (p *Parser) Errors() []string {
+	return p.errors
 }
 
-func (p *Parser) gotError(t token.TokenType) {
-	fmt.Println("error while parsing", t)
+// errorf records a parse error pointing at tok
+func (p *Parser) errorf(tok token.Token, format string, a ...interface{}) {
+	msg := fmt.Sprintf("line %d, column %d: ", tok.Line, tok.Column) + fmt.Sprintf(format, a...)
+	p.errors = append(p.errors, msg)
 }
@@ -5,6 +5,8 @@ import (
 	"limLang/ast"
 	"limLang/lexer"
 	"limLang/token"
+	"math"
+	"strings"
 	"testing"
 )
 
@@ -49,23 +51,22 @@ func TestBoolStatements(t *testing.T) {
 	l := lexer.New(input)
 	p := New(l)
 	program := p.ParseProgram()
-	fmt.Println(program.Statements)
 	if program == nil {
 		t.Fatal("ParseProgram returned nil")
 	}
-	if len(program.Statements) != 3 {
-		t.Fatalf("program.Statements does not contain 3 statements. got=%d", len(program.Statements))
+	if len(p.Errors()) != 0 {
+		t.Fatalf("unexpected parse errors: %v", p.Errors())
+	}
+	names := []string{"y", "x", "a"}
+	if len(program.Statements) != len(names) {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d", len(names), len(program.Statements))
+	}
+	for i, name := range names {
+		stmt, ok := program.Statements[i].(*ast.BoolStatement)
+		if !ok || stmt.Name.Value != name {
+			t.Errorf("statements[%d] is not the declaration of %s. got=%#v", i, name, program.Statements[i])
+		}
 	}
-	// tests := []struct {
-	// 	expectedIdentifier    string
-	// 	expectedIdentiferType string
-	// }{
-	// 	{"y", token.BOOL},
-	// }
-
-	// for i, tt := range tests {
-	// 	stmt := program.Statements[i]
-	// }
 }
 
 func TestStringStatements(t *testing.T) {
@@ -78,23 +79,22 @@ func TestStringStatements(t *testing.T) {
 	l := lexer.New(input)
 	p := New(l)
 	program := p.ParseProgram()
-	fmt.Println(program.Statements)
 	if program == nil {
 		t.Fatal("ParseProgram returned nil")
 	}
-	if len(program.Statements) != 3 {
-		t.Fatalf("program.Statements does not contain 3 statements. got=%d", len(program.Statements))
+	if len(p.Errors()) != 0 {
+		t.Fatalf("unexpected parse errors: %v", p.Errors())
+	}
+	names := []string{"a", "b", "c", "diss"}
+	if len(program.Statements) != len(names) {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d", len(names), len(program.Statements))
+	}
+	for i, name := range names {
+		stmt, ok := program.Statements[i].(*ast.StringStatement)
+		if !ok || stmt.Name.Value != name {
+			t.Errorf("statements[%d] is not the declaration of %s. got=%#v", i, name, program.Statements[i])
+		}
 	}
-	// tests := []struct {
-	// 	expectedIdentifier    string
-	// 	expectedIdentiferType string
-	// }{
-	// 	{"y", token.BOOL},
-	// }
-
-	// for i, tt := range tests {
-	// 	stmt := program.Statements[i]
-	// }
 }
 
 func TestDefineStatements(t *testing.T) {
@@ -201,6 +201,10 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"(3 < 5 == true) == false",
 			"(((3 < 5) == true) == false)",
 		},
+		{
+			"a <= b + 1 == b >= a",
+			"(( a <= ( b + 1)) == ( b >=  a))",
+		},
 	}
 
 	for _, tt := range tests {
@@ -364,3 +368,381 @@ func TestStruct(t *testing.T) {
 	// }
 	// fmt.Println(stmt1)
 }
+
+func TestVariadicFunctionParameters(t *testing.T) {
+	input := `fn sum(int first, int ...rest) int {
+		return first
+	}`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Body does not contain %d statements. got=%d\n",
+			1, len(program.Statements))
+	}
+	stmt, ok := program.Statements[0].(*ast.FunctionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.FunctionStatement. got=%T", program.Statements[0])
+	}
+	if len(stmt.Parameters) != 2 {
+		t.Fatalf("function should have 2 parameters got=%d", len(stmt.Parameters))
+	}
+	if stmt.Parameters[0].Variadic {
+		t.Fatalf("parameter `first` should not be variadic")
+	}
+	if !stmt.Parameters[1].Variadic || stmt.Parameters[1].Value != "rest" {
+		t.Fatalf("parameter `rest` should be variadic got=%s", stmt.Parameters[1])
+	}
+	if stmt.Parameters[1].String() != "int ...rest" {
+		t.Fatalf("wrong parameter string got=%q", stmt.Parameters[1].String())
+	}
+}
+
+func TestMemberExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"user.name", " user.name"},
+		{"user.address.city", " user.address.city"},
+		{"user.Greet(1 + 2)", " user.Greet((1 + 2))"},
+		{"user.age = user.age + 1", " user.age = ( user.age + 1)"},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("unexpected parse errors for %q: %v", tt.input, p.Errors())
+		}
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+		if program.String() != tt.expected {
+			t.Errorf("wrong program. expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestFloatLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"3.14", "3.14"},
+		{"1 + 2.5", "(1 + 2.5)"},
+		{"float x = 0.5", "float x = 0.5"},
+		{"math.pi", " math.pi"},
+		{"fn half(float x) float { return x / 2.0 }", ""},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("unexpected parse errors for %q: %v", tt.input, p.Errors())
+		}
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+		if tt.expected != "" && program.String() != tt.expected {
+			t.Errorf("wrong program. expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+
+	program := New(lexer.New("2.75")).ParseProgram()
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	literal, ok := stmt.Expression.(*ast.FloatLiteral)
+	if !ok || literal.Value != 2.75 {
+		t.Errorf("expected a float literal with value 2.75. got=%T (%+v)", stmt.Expression, stmt.Expression)
+	}
+}
+
+func TestIntegerLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"0xFF", 255},
+		{"0XfF", 255},
+		{"0o17", 15},
+		{"0b1010", 10},
+		{"0", 0},
+		{"0o10", 8},
+		{"0x_ff", 255},
+		{"1_000_000", 1000000},
+		{"9223372036854775807", math.MaxInt64},
+		{"-9223372036854775808", math.MinInt64},
+		{"-0x8000_0000_0000_0000", math.MinInt64},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("unexpected parse errors for %q: %v", tt.input, p.Errors())
+		}
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+		if !ok || literal.Value != tt.expected {
+			t.Errorf("%q: expected an integer literal with value %d. got=%T (%+v)", tt.input, tt.expected, stmt.Expression, stmt.Expression)
+		}
+	}
+}
+
+func TestIntegerLiteralErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"9223372036854775808", "line 1, column 1: integer literal 9223372036854775808 is out of range"},
+		{"int a = 1\nint b = 0x1_0000_0000_0000_0000", "line 2, column 9: integer literal 0x1_0000_0000_0000_0000 is out of range"},
+		{"0b102", "line 1, column 1: invalid integer literal 0b102"},
+		{"1__000", "line 1, column 1: invalid integer literal 1__000"},
+		{"0x", "line 1, column 1: invalid integer literal 0x"},
+		{"010", "line 1, column 1: integer literal 010 can't start with 0, use the 0o prefix for octal"},
+		{"09", "line 1, column 1: integer literal 09 can't start with 0, use the 0o prefix for octal"},
+		{"1_", "line 1, column 1: invalid integer literal 1_"},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 || p.Errors()[0] != tt.expected {
+			t.Errorf("%q: wrong errors. got=%q, want=%q", tt.input, p.Errors(), tt.expected)
+		}
+	}
+}
+
+func TestDocComments(t *testing.T) {
+	input := `// not attached, a blank line follows
+
+// add adds a and b.
+// It never fails.
+fn add(int a, int b) int { return a + b }
+/*
+   sub subtracts b from a
+*/
+fn sub(int a, int b) int { return a - b }
+int x = 1 // about x, not the next fn
+fn none() int { return 1 }
+fn inner() int {
+	// not a declaration
+	return 1
+}
+// limit is the most we take
+string? limit = null`
+	l := lexer.New(input)
+	l.KeepComments = true
+	p := New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("unexpected parse errors: %v", p.Errors())
+	}
+
+	expected := map[string]string{
+		"add":   "add adds a and b.\nIt never fails.",
+		"sub":   "sub subtracts b from a",
+		"none":  "",
+		"inner": "",
+	}
+	found := 0
+	for _, stmt := range program.Statements {
+		fn, ok := stmt.(*ast.FunctionStatement)
+		if !ok {
+			continue
+		}
+		found++
+		if fn.Doc != expected[fn.FnName] {
+			t.Errorf("wrong doc for %s. expected=%q, got=%q", fn.FnName, expected[fn.FnName], fn.Doc)
+		}
+	}
+	if found != len(expected) {
+		t.Fatalf("expected %d functions. got=%d", len(expected), found)
+	}
+	if x := program.Statements[2].(*ast.IntStatement); x.Doc != "" {
+		t.Errorf("trailing comments aren't docs. got=%q", x.Doc)
+	}
+	last := program.Statements[len(program.Statements)-1]
+	if limit, ok := last.(*ast.StringStatement); !ok || limit.Doc != "limit is the most we take" {
+		t.Errorf("wrong doc for limit. got=%#v", last)
+	}
+
+	// without KeepComments the program is the same and has no docs
+	program = New(lexer.New(input)).ParseProgram()
+	if fn := program.Statements[0].(*ast.FunctionStatement); fn.Doc != "" {
+		t.Errorf("expected no doc without KeepComments. got=%q", fn.Doc)
+	}
+}
+
+func TestStatementTermination(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"int a = 1 +\n\t2", []string{"int a = (1 + 2)"}},
+		{"f(1,\n2\n)", []string{" f(1, 2)"}},
+		{"int []a = [\n1,\n2\n]", []string{"[1, 2]"}},
+		{"a\n-b", []string{" a", "(- b)"}},
+		{"f\n(x)", []string{" f", " x"}},
+		{"int a = 1; int b = 2;;", []string{"int a = 1", "int b = 2"}},
+		{"int a\nstring s", []string{"int a = int", "string s= "}},
+		{"fn f() int\n{\n\treturn\n}\nf()", []string{"fn f() int {\nreturn \n}", " f()"}},
+		{"if a { b } else { c } d", []string{"if  a {\n b\n}else {\n c\n}", " d"}},
+		{`"a ${b +
+c}"`, []string{`"a ${( b +  c)}"`}},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Errorf("%q: unexpected parse errors: %v", tt.input, p.Errors())
+			continue
+		}
+		got := []string{}
+		for _, stmt := range program.Statements {
+			got = append(got, stmt.String())
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.expected) {
+			t.Errorf("%q: wrong statements. expected=%q, got=%q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestStatementTerminationErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"int a = 1 int b = 2", []string{`line 1, column 11: expected ; or a new line after the statement, got "int"`}},
+		{"f(x) g(y)\nint c = 3 4", []string{
+			`line 1, column 6: expected ; or a new line after the statement, got "g"`,
+			`line 2, column 11: expected ; or a new line after the statement, got "4"`,
+		}},
+		{"x := y", []string{`line 1, column 6: can't tell the type of x from "y", declare it with a type instead of :=`}},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		if fmt.Sprint(p.Errors()) != fmt.Sprint(tt.expected) {
+			t.Errorf("%q: wrong errors. got=%q, want=%q", tt.input, p.Errors(), tt.expected)
+		}
+	}
+}
+
+func TestInterpolatedStrings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"hello ${name}!"`, `"hello ${ name}!"`},
+		{`"${a + 1} and ${b}"`, `"${( a + 1)} and ${ b}"`},
+		{`"${ "in" + "ner" }"`, `"${(in + ner)}"`},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("unexpected parse errors for %q: %v", tt.input, p.Errors())
+		}
+		if program.String() != tt.expected {
+			t.Errorf("wrong program. expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+
+	p := New(lexer.New(`"a ${} b"`))
+	p.ParseProgram()
+	if len(p.Errors()) == 0 || !strings.Contains(p.Errors()[0], "empty ${} in string") {
+		t.Errorf("expected an error for an empty interpolation. got=%v", p.Errors())
+	}
+}
+
+func TestLexerErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"string s = \"open", []string{"line 1, column 12: unterminated string"}},
+		{"int a = 1\nstring s = \"bad \\q\"\nint b = 2", []string{`line 2, column 12: unknown escape sequence \q`}},
+		{"int a = 1\nstring s = \"é\" € 2", []string{"line 2, column 16: illegal character '€'"}},
+		{"int 変数 = 12abc", []string{"line 1, column 10: invalid character 'a' in number"}},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		if fmt.Sprint(p.Errors()) != fmt.Sprint(tt.expected) {
+			t.Errorf("%q: wrong errors. got=%q, want=%q", tt.input, p.Errors(), tt.expected)
+		}
+	}
+}
+
+func TestNullable(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"int? a = null", "int? a = null"},
+		{"string? s", "string? s= null"},
+		{"a ?? b == c", "( a ?? ( b ==  c))"},
+		{"a ?? b ?? c", "(( a ??  b) ??  c)"},
+		{"a?.b.c", " a?.b.c"},
+		{"fn f(int? a, string b) float? {\nreturn null\n}", "fn f(int? a, string b) float? {\nreturn null\n}"},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Errorf("%q: unexpected parse errors: %v", tt.input, p.Errors())
+			continue
+		}
+		if len(program.Statements) != 1 {
+			t.Errorf("%q: expected 1 statement. got=%d", tt.input, len(program.Statements))
+			continue
+		}
+		if got := program.Statements[0].String(); got != tt.expected {
+			t.Errorf("%q: wrong statement. expected=%q, got=%q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestPubAndConst(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"pub int a = 1", "pub int a = 1"},
+		{"const float pi = 3.14", "const float pi = 3.14"},
+		{"pub const string? s = null", "pub const string? s= null"},
+		{"pub fn f() bool { return true }", "pub fn f() bool {\nreturn true\n}"},
+	}
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Errorf("%q: unexpected parse errors: %v", tt.input, p.Errors())
+			continue
+		}
+		if len(program.Statements) != 1 {
+			t.Errorf("%q: expected 1 statement. got=%d", tt.input, len(program.Statements))
+			continue
+		}
+		if got := program.Statements[0].String(); got != tt.expected {
+			t.Errorf("%q: wrong statement. expected=%q, got=%q", tt.input, tt.expected, got)
+		}
+	}
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{"const int a", "line 1, column 11: constant a needs a value"},
+		{"const fn f() {}", "line 1, column 7: const goes before a typed variable, like `const int x = 1`, got \"fn\""},
+		{"pub a = 1", "line 1, column 5: pub goes before fn, const or a typed variable, got \"a\""},
+		{"pub int []a = [1]", "line 1, column 5: pub goes before fn, const or a typed variable, got \"int\""},
+	}
+	for _, tt := range errors {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 || p.Errors()[0] != tt.expected {
+			t.Errorf("%q: wrong errors. expected=%q first, got=%q", tt.input, tt.expected, p.Errors())
+		}
+	}
+}
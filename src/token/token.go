@@ -5,11 +5,29 @@ type TokenType string
 type Token struct {
 	Type    TokenType
 	Literal string
+
+	// position of the first character of the token, both start at 1
+	Line   int
+	Column int
+
+	// the comments around the token, only set when the lexer keeps
+	// comments and there are some
+	Trivia *Trivia
+}
+
+// Trivia are the comments next to a token, each one a COMMENT token.
+// Leading are the ones between the previous token (or its trailing
+// comments) and this one, newlines in between don't count as tokens here.
+// Trailing are the ones after the token up to the end of its line.
+type Trivia struct {
+	Leading  []Token
+	Trailing []Token
 }
 
 const (
 	ILLEGAL = "ILLEGAL"
 	EOF     = "EOF"
+	COMMENT = "COMMENT" // the comments in Trivia
 
 	// Identifiers + literals
 	IDENT          = "IDENT"          // add, foobar, x, y, ...
@@ -23,6 +41,13 @@ const (
 	BOOL   = "BOOL"
 	STRING = "STRING"
 
+	// a string with ${...} in it is split around the expressions, like
+	// "a ${x} b ${y} c" is TEMPLATE_HEAD(a ) x TEMPLATE_MIDDLE( b ) y
+	// TEMPLATE_TAIL( c)
+	TEMPLATE_HEAD   = "TEMPLATE_HEAD"
+	TEMPLATE_MIDDLE = "TEMPLATE_MIDDLE"
+	TEMPLATE_TAIL   = "TEMPLATE_TAIL"
+
 	// Operators
 	ASSIGN   = "="
 	PLUS     = "+"
@@ -56,9 +81,14 @@ const (
 	SEMICOLON = ";"
 	COLON     = ":"
 
-	PERIOD = "."
-	ARROW  = "->"
-	DEFINE = ":="
+	PERIOD   = "."
+	ELLIPSIS = "..."
+	ARROW    = "->"
+	DEFINE   = ":="
+
+	QUESTION     = "?"
+	NULLISH      = "??"
+	OPTIONAL_DOT = "?."
 
 	LPAREN = "("
 	RPAREN = ")"
@@ -72,6 +102,7 @@ const (
 	// Keywords
 	FUNCTION  = "FUNCTION"
 	CONST     = "CONST"
+	PUB       = "PUB"
 	TRUE      = "TRUE"
 	FALSE     = "FALSE"
 	IF        = "IF"
@@ -95,6 +126,7 @@ var keywords = map[string]TokenType{
 	"else":   ELSE,
 	"return": RETURN,
 	"const":  CONST,
+	"pub":    PUB,
 	"null":   NULL,
 
 	"int":    Keyword_INT,